@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"glitch-saver/internal/config"
+	"glitch-saver/internal/tui"
+)
+
+// loadKeymap returns the default key bindings with the bindings of the
+// config file's keys table applied. file may be nil.
+func loadKeymap(file *config.File) tui.Keymap {
+	km := tui.DefaultKeymap()
+	if file != nil {
+		if err := km.Apply(file.Keys); err != nil {
+			log.Fatalf("invalid key bindings in %s: %v", file.Path, err)
+		}
+	}
+	return km
+}
+
+// runKeys implements the "keys" subcommand, which prints the effective key
+// bindings.
+func runKeys(args []string) {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Usage of glitch-saver keys:\n  keys    print the key bindings, including those set in the config file")
+		os.Exit(2)
+	}
+	km := loadKeymap(loadConfig())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, a := range tui.Actions() {
+		var keys []string
+		for _, k := range km.Keys(a.Name) {
+			keys = append(keys, k.String())
+		}
+		if keys == nil {
+			keys = []string{"none"}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", a.Name, strings.Join(keys, " "), a.Help)
+	}
+	w.Flush()
+}
@@ -1,29 +1,144 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"log"
 	"os"
+	"time"
 
+	"glitch-saver/internal/config"
+	"glitch-saver/internal/headless"
 	"glitch-saver/internal/options"
+	"glitch-saver/internal/preset"
 	"glitch-saver/internal/tui"
 )
 
 func main() {
-	opts := options.ParseOptions()
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "record":
+			runRecord(args[1:])
+			return
+		case "preset":
+			runPreset(args[1:])
+			return
+		case "keys":
+			runKeys(args[1:])
+			return
+		}
+	}
+
+	fs := flag.CommandLine
+	headlessMode := fs.Bool("headless", false, "render frames to files instead of the terminal")
+	headlessOut := fs.String("out", "frames", "directory to write headless frames to")
+	headlessWidth := fs.Int("width", 80, "width of the virtual screen in headless mode")
+	headlessHeight := fs.Int("height", 24, "height of the virtual screen in headless mode")
+	headlessFrames := fs.Int("frames", 100, "number of frames to render in headless mode")
+	opts, file := loadOptions(fs, args)
+
+	if *headlessMode {
+		cfg := headless.Config{Width: *headlessWidth, Height: *headlessHeight, Frames: *headlessFrames}
+		if err := headless.WriteFrames(*headlessOut, opts, cfg); err != nil {
+			log.Fatalf("headless rendering failed: %v", err)
+		}
+		return
+	}
+
+	keymap := loadKeymap(file)
+	log.Println("Calling RunTUI")
+	s, err := tui.RunTUI(opts, keymap)
+	if err != nil {
+		log.Fatalf("TUI application failed: %v", err)
+	}
+	log.Println("RunTUI returned successfully, finalizing screen.")
+	// Ensure the screen is finalized
+	if s != nil {
+		s.Fini()
+	}
+	log.Println("Application exited normally.")
+}
+
+// loadOptions parses args with fs, which may already hold command specific
+// flags, and resolves the options from every configuration source. Later
+// sources override earlier ones: defaults, the config file, the presets named
+// by -preset and -load-preset, GLITCH_SAVER_* environment variables and
+// finally the flags given on the command line. Problems with the options are
+// logged, or fatal with -strict. It also applies -save-preset. The config
+// file is returned for its other settings, or nil if there is none.
+func loadOptions(fs *flag.FlagSet, args []string) (*options.GlitchOptions, *config.File) {
+	if _, err := options.ParseArgs(fs, args); err != nil {
+		log.Fatalf("failed to parse options: %v", err)
+	}
+
+	var layers []options.Layer
+	file := loadConfig()
+	if file != nil {
+		log.Printf("Using config %s", file.Path)
+		layers = append(layers, options.Layer{Source: file.Path, Values: file.Values})
+	}
+	env := options.EnvLayer(os.LookupEnv)
+	flags := options.FlagLayer(fs)
 
-	if opts.LoadPreset != "" {
-		data, err := os.ReadFile(opts.LoadPreset)
+	// -preset and -load-preset may themselves come from any source, so
+	// resolve once without presets to find them
+	opts, issues, err := options.Resolve(append(layers, env, flags)...)
+	if err != nil {
+		log.Fatalf("invalid options: %v", err)
+	}
+	if opts.Preset != "" || opts.LoadPreset != "" {
+		if opts.Preset != "" {
+			store, err := preset.DefaultStore()
+			if err != nil {
+				log.Fatalf("failed to locate preset directory: %v", err)
+			}
+			layer, err := store.Load(opts.Preset)
+			if err != nil {
+				log.Fatalf("failed to load preset: %v", err)
+			}
+			layers = append(layers, layer)
+		}
+		if opts.LoadPreset != "" {
+			data, err := os.ReadFile(opts.LoadPreset)
+			if err != nil {
+				log.Fatalf("failed to read preset file: %v", err)
+			}
+			layer, err := options.PresetLayer(opts.LoadPreset, data)
+			if err != nil {
+				log.Fatalf("failed to load preset file: %v", err)
+			}
+			layers = append(layers, layer)
+		}
+		opts, issues, err = options.Resolve(append(layers, env, flags)...)
 		if err != nil {
-			log.Fatalf("failed to read preset file: %v", err)
+			log.Fatalf("invalid options: %v", err)
 		}
-		if err := json.Unmarshal(data, opts); err != nil {
-			log.Fatalf("failed to unmarshal preset file: %v", err)
+	}
+
+	problems := 0
+	for _, l := range layers {
+		for _, w := range l.Warnings {
+			log.Printf("warning: %s", w)
+			problems++
 		}
 	}
+	for _, issue := range issues {
+		log.Print(issue)
+		problems++
+	}
+	if opts.Strict && problems > 0 {
+		log.Fatalf("refusing to start with %d option problem(s) in -strict mode", problems)
+	}
+
+	// Pick a seed now rather than in the engine so that it can be reported
+	// and a run can be reproduced with -seed or the preset saved below
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	log.Printf("Using seed %d", opts.Seed)
 
 	if opts.SavePreset != "" {
-		data, err := json.MarshalIndent(opts, "", "  ")
+		data, err := options.MarshalPreset(opts)
 		if err != nil {
 			log.Fatalf("failed to marshal preset: %v", err)
 		}
@@ -32,15 +147,18 @@ func main() {
 		}
 	}
 
-	log.Println("Calling RunTUI")
-	s, err := tui.RunTUI(opts)
+	return opts, file
+}
+
+// loadConfig reads the config file, returning nil if there is none.
+func loadConfig() *config.File {
+	dir, err := config.Dir()
 	if err != nil {
-		log.Fatalf("TUI application failed: %v", err)
+		return nil
 	}
-	log.Println("RunTUI returned successfully, finalizing screen.")
-	// Ensure the screen is finalized
-	if s != nil {
-		s.Fini()
+	file, err := config.Load(dir)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
 	}
-	log.Println("Application exited normally.")
+	return file
 }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"glitch-saver/internal/options"
+	"glitch-saver/internal/preset"
+)
+
+const presetUsage = `Usage of glitch-saver preset:
+  preset list                  list the stored and built-in presets
+  preset show NAME             print a preset
+  preset save NAME [options]   save the given option flags as a preset
+  preset delete NAME           delete a preset
+  preset export NAME [FILE]    write a preset to FILE or standard output
+  preset import FILE [NAME]    store a preset file, named after FILE by default
+
+Presets are stored in %s. A stored preset
+shadows a built-in preset with the same name.
+`
+
+// runPreset implements the "preset" subcommand, which manages the library of
+// named presets loaded with -preset.
+func runPreset(args []string) {
+	store, err := preset.DefaultStore()
+	if err != nil {
+		log.Fatalf("failed to locate preset directory: %v", err)
+	}
+	if len(args) == 0 {
+		presetUsageExit(store)
+	}
+
+	cmd, args := args[0], args[1:]
+	switch {
+	case cmd == "list" && len(args) == 0:
+		names, err := store.List()
+		if err != nil {
+			log.Fatalf("failed to list presets: %v", err)
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		for _, name := range preset.BuiltinNames() {
+			if !slices.Contains(names, name) {
+				fmt.Printf("%s (built-in)\n", name)
+			}
+		}
+
+	case (cmd == "show" && len(args) == 1) || (cmd == "export" && len(args) <= 2 && len(args) > 0):
+		data, err := store.Read(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(args) == 2 {
+			err = os.WriteFile(args[1], data, 0644)
+		} else {
+			_, err = os.Stdout.Write(data)
+		}
+		if err != nil {
+			log.Fatalf("failed to export preset: %v", err)
+		}
+
+	case cmd == "save" && len(args) > 0:
+		fs := flag.NewFlagSet("preset save", flag.ExitOnError)
+		if _, err := options.ParseArgs(fs, args[1:]); err != nil {
+			log.Fatalf("failed to parse options: %v", err)
+		}
+		savePreset(store, args[0], options.FlagLayer(fs))
+
+	case cmd == "delete" && len(args) == 1:
+		if err := store.Delete(args[0]); err != nil {
+			log.Fatal(err)
+		}
+
+	case cmd == "import" && len(args) > 0 && len(args) <= 2:
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			log.Fatalf("failed to read preset file: %v", err)
+		}
+		layer, err := options.PresetLayer(args[0], data)
+		if err != nil {
+			log.Fatalf("failed to load preset file: %v", err)
+		}
+		name := strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		if len(args) == 2 {
+			name = args[1]
+		}
+		savePreset(store, name, layer)
+
+	default:
+		presetUsageExit(store)
+	}
+}
+
+// savePreset stores the options set by layer as the named preset.
+func savePreset(store *preset.Store, name string, layer options.Layer) {
+	for _, w := range layer.Warnings {
+		log.Printf("warning: %s", w)
+	}
+	opts, issues, err := options.Resolve(layer)
+	if err != nil {
+		log.Fatalf("invalid options: %v", err)
+	}
+	for _, issue := range issues {
+		log.Print(issue)
+	}
+	if err := store.Save(name, opts); err != nil {
+		log.Fatalf("failed to save preset: %v", err)
+	}
+	log.Printf("Saved preset %q to %s", name, store.Path(name))
+}
+
+func presetUsageExit(store *preset.Store) {
+	fmt.Fprintf(os.Stderr, presetUsage, store.Dir)
+	os.Exit(2)
+}
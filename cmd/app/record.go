@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"glitch-saver/internal/export"
+	"glitch-saver/internal/frame"
+	"glitch-saver/internal/headless"
+)
+
+// runRecord implements the "record" subcommand, which renders the animation
+// to a file instead of the terminal: an asciinema v2 recording, an animated
+// GIF or an HTML player. It accepts every option flag, including
+// -load-preset, so a preset maps directly to a recording.
+func runRecord(args []string) {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	out := fs.String("o", "glitch.cast", "file to write the recording to")
+	format := fs.String("format", "", "output format: "+strings.Join(export.Formats, ", ")+" (default: from the -o extension)")
+	duration := fs.Duration("duration", 10*time.Second, "length of the recording")
+	width := fs.Int("width", 80, "width of the recorded terminal")
+	height := fs.Int("height", 24, "height of the recorded terminal")
+	opts, _ := loadOptions(fs, args)
+
+	cfg := headless.Config{
+		Width:  *width,
+		Height: *height,
+		Frames: int(duration.Seconds() * float64(opts.FPS)),
+	}
+	if cfg.Frames < 1 {
+		log.Fatalf("-duration %v is too short to record a frame at %d fps", *duration, opts.FPS)
+	}
+
+	if *format == "" {
+		*format = export.FormatFromPath(*out)
+	}
+	if !slices.Contains(export.Formats, *format) {
+		log.Fatalf("unknown output format %q (supported: %s)", *format, strings.Join(export.Formats, ", "))
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("failed to create recording: %v", err)
+	}
+
+	w, err := export.New(*format, f, cfg.Width, cfg.Height, opts.FPS)
+	if err != nil {
+		log.Fatalf("failed to write recording: %v", err)
+	}
+	// Recordings are played back elsewhere, so only limit their colors when
+	// asked to explicitly
+	mode, _ := frame.ParseColorMode(opts.ColorMode)
+	err = headless.Render(opts, cfg, func(_ int, g *frame.Grid) error {
+		return w.WriteFrame(mode.Convert(g))
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	// Closing the file can report a failed write too
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Fatalf("failed to write recording: %v", err)
+	}
+}
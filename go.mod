@@ -2,12 +2,14 @@ module glitch-saver
 
 go 1.25.4
 
-require github.com/gdamore/tcell/v2 v2.13.1
+require (
+	github.com/gdamore/tcell/v2 v2.13.1
+	github.com/rivo/uniseg v0.4.7
+)
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.3.0 // indirect
-	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/term v0.37.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
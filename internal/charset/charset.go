@@ -0,0 +1,195 @@
+// Package charset provides the character sets glitch characters are drawn
+// from. A Set is a weighted union of named sets, described by a spec such as
+// "ascii:1,katakana:3": each entry is a set name with an optional weight, and
+// a set is picked in proportion to its weight before a character is picked
+// uniformly from it.
+//
+// Set names refer to the built-in sets, to "custom" for the characters given
+// on the command line, or to a file named NAME.txt in the charsets directory
+// of the config dir. An entry containing a path separator or ending in .txt
+// is read as a file path.
+package charset
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"glitch-saver/internal/config"
+)
+
+// Custom is the set name that refers to the inline characters passed to Parse.
+const Custom = "custom"
+
+var builtin = map[string]string{
+	"ascii":       "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!@#$%^&*()_+-=[]{}|;':\",./<>?`~ ",
+	"cp437":       "ÇüéâäàåçêëèïîìÄÅÉæÆôöòûùÿÖÜ¢£¥₧ƒáíóúñÑªº¿⌐¬½¼¡«»░▒▓│┤╡╢╖╕╣║╗╝╜⛛┐└┴┬├─┼╞╟╚╔╩╦╠═╬╧╨╤╥╙╘╒╓╫╪┘┌█▄▌▐▀αßΓπΣσµτΦΘΩδ∞φε∩≡±≥≤⌠⌡÷≈°∙·√ⁿ²■ ",
+	"blocks":      "░▒▓█",
+	"braille":     runeRange(0x2801, 0x28FF),
+	"box-drawing": "─│┌┐└┘├┤┬┴┼═║╔╗╚╝╠╣╦╩╬╒╓╕╖╘╙╛╜╞╟╡╢╤╥╧╨╪╫",
+	"katakana":    runeRange(0x30A1, 0x30FA),
+	"runic":       runeRange(0x16A0, 0x16EA),
+}
+
+// aliases maps other names of built-in sets to their documented names.
+var aliases = map[string]string{"box": "box-drawing"}
+
+// Names lists the built-in sets in the order they are documented.
+var Names = []string{"ascii", "cp437", "blocks", "braille", "box-drawing", "katakana", "runic"}
+
+// runeRange returns the characters from lo to hi inclusive.
+func runeRange(lo, hi rune) string {
+	var b strings.Builder
+	for r := lo; r <= hi; r++ {
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Builtin returns the characters of the named built-in set.
+func Builtin(name string) ([]rune, bool) {
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	s, ok := builtin[name]
+	return []rune(s), ok
+}
+
+type part struct {
+	runes  []rune
+	weight float64
+}
+
+// Set is a weighted union of character sets.
+type Set struct {
+	parts []part
+	total float64
+}
+
+// New returns a set that picks uniformly from runes, which must not be empty.
+func New(runes []rune) *Set {
+	return &Set{parts: []part{{runes: runes, weight: 1}}, total: 1}
+}
+
+// Legacy returns the set selected by the -blocks and -cp437 options: ASCII by
+// default, the blocks or CP437 characters, or both pooled together.
+func Legacy(useBlocks, useCP437 bool) *Set {
+	switch {
+	case useBlocks && useCP437:
+		return New([]rune(builtin["blocks"] + builtin["cp437"]))
+	case useBlocks:
+		return New([]rune(builtin["blocks"]))
+	case useCP437:
+		return New([]rune(builtin["cp437"]))
+	}
+	return New([]rune(builtin["ascii"]))
+}
+
+// Pick returns a random character from the set.
+func (s *Set) Pick(r *rand.Rand) rune {
+	p := s.parts[0]
+	if len(s.parts) > 1 {
+		x := r.Float64() * s.total
+		for _, p = range s.parts {
+			if x < p.weight {
+				break
+			}
+			x -= p.weight
+		}
+	}
+	return p.runes[r.Intn(len(p.runes))]
+}
+
+// Dir returns the directory charset files are loaded from.
+func Dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "charsets"), nil
+}
+
+// Parse builds a set from a spec like "ascii:1,katakana:3". custom holds the
+// characters of the "custom" set.
+func Parse(spec, custom string) (*Set, error) {
+	s := &Set{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		name, weight := entry, 1.0
+		// Only a number after the last colon is a weight, leaving paths
+		// such as C:\sets\x.txt intact
+		if i := strings.LastIndex(entry, ":"); i >= 0 {
+			if w, err := strconv.ParseFloat(entry[i+1:], 64); err == nil {
+				if !(w > 0) || math.IsInf(w, 1) {
+					return nil, fmt.Errorf("charset %q: weight must be a positive number", entry)
+				}
+				name, weight = entry[:i], w
+			}
+		}
+		runes, err := load(name, custom)
+		if err != nil {
+			return nil, err
+		}
+		s.parts = append(s.parts, part{runes: runes, weight: weight})
+		s.total += weight
+	}
+	return s, nil
+}
+
+// load returns the characters of a single named set.
+func load(name, custom string) ([]rune, error) {
+	if runes, ok := Builtin(name); ok {
+		return runes, nil
+	}
+	switch {
+	case name == "":
+		return nil, errors.New("empty charset name")
+	case name == Custom:
+		if custom == "" {
+			return nil, errors.New("charset \"custom\" needs -charset-custom")
+		}
+		return []rune(custom), nil
+	case strings.ContainsRune(name, filepath.Separator) || strings.ContainsRune(name, '/') || strings.HasSuffix(name, ".txt"):
+		return loadFile(name)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	runes, err := loadFile(filepath.Join(dir, name+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("unknown charset %q (built in: %s)", name, strings.Join(Names, ", "))
+	}
+	return runes, err
+}
+
+// loadFile reads a charset file. Every character other than white space is
+// part of the set; lines starting with # are comments.
+func loadFile(path string) ([]rune, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var runes []rune
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, r := range line {
+			if !unicode.IsSpace(r) {
+				runes = append(runes, r)
+			}
+		}
+	}
+	if len(runes) == 0 {
+		return nil, fmt.Errorf("charset file %s has no characters", path)
+	}
+	return runes, nil
+}
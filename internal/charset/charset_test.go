@@ -0,0 +1,103 @@
+package charset
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	s, err := Parse("blocks:3, custom", "xy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.parts) != 2 || s.total != 4 {
+		t.Fatalf("Parse = %+v, want two parts with total weight 4", s)
+	}
+
+	counts := map[bool]int{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 4000; i++ {
+		c := s.Pick(r)
+		counts[c == 'x' || c == 'y']++
+	}
+	if counts[true] < 800 || counts[true] > 1200 {
+		t.Errorf("picked the custom set %d times out of 4000, want about 1000", counts[true])
+	}
+
+	for _, spec := range []string{"", "ascii,", "ascii:0", "ascii:-1", "ascii:NaN", "ascii:x", "custom", "nosuchset"} {
+		if _, err := Parse(spec, ""); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestPickSingleSet(t *testing.T) {
+	// A set with a single part must draw exactly like picking from the
+	// runes directly, so that existing seeds keep rendering the same frames
+	runes := []rune(builtin["ascii"])
+	r1, r2 := rand.New(rand.NewSource(7)), rand.New(rand.NewSource(7))
+	s := Legacy(false, false)
+	for i := 0; i < 100; i++ {
+		if got, want := s.Pick(r1), runes[r2.Intn(len(runes))]; got != want {
+			t.Fatalf("Pick %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	charsets := filepath.Join(dir, "glitch-saver", "charsets")
+	if err := os.MkdirAll(charsets, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(charsets, "stars.txt"), []byte("# stars\n* ✦\n✧\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "empty.txt"), []byte("# nothing\n \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, spec := range []string{"stars", filepath.Join(charsets, "stars.txt")} {
+		s, err := Parse(spec, "")
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", spec, err)
+		}
+		if got := string(s.parts[0].runes); got != "*✦✧" {
+			t.Errorf("Parse(%q) loaded %q, want %q", spec, got, "*✦✧")
+		}
+	}
+
+	// A colon is only taken for a weight when a number follows it
+	colon := filepath.Join(dir, "a:b.txt")
+	if err := os.WriteFile(colon, []byte("ab"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for spec, weight := range map[string]float64{colon: 1, colon + ":2.5": 2.5} {
+		s, err := Parse(spec, "")
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", spec, err)
+		}
+		if got := string(s.parts[0].runes); got != "ab" || s.total != weight {
+			t.Errorf("Parse(%q) loaded %q with weight %v, want %q with weight %v", spec, got, s.total, "ab", weight)
+		}
+	}
+
+	if _, err := Parse(filepath.Join(dir, "empty.txt"), ""); err == nil {
+		t.Error("Parse of an empty charset file succeeded, want an error")
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	for _, name := range Names {
+		if _, err := Parse(name, ""); err != nil {
+			t.Errorf("built-in charset %s: %v", name, err)
+		}
+	}
+	box, _ := Builtin("box-drawing")
+	if alias, ok := Builtin("box"); !ok || string(alias) != string(box) {
+		t.Error("box is not an alias of box-drawing")
+	}
+}
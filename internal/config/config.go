@@ -0,0 +1,152 @@
+// Package config locates and reads the user's configuration file, which holds
+// the baseline options applied before presets, the environment and flags.
+//
+// The file lives in $XDG_CONFIG_HOME/glitch-saver, falling back to
+// ~/.config/glitch-saver, and is named config.json or config.toml. Both
+// formats map option names to values:
+//
+//	fps = 20
+//	melt = true
+//	scroll-direction = "vertical"
+//
+// Nested objects and TOML tables are flattened with dots, so a key "b" in a
+// table "a" is returned as "a.b". The "keys" table is the exception: it binds
+// keys to the actions of the running screensaver and is kept apart from the
+// options.
+//
+//	[keys]
+//	pause = "p space"
+//	toggle-melt = "F5"
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Names lists the file names a config file may have.
+var Names = []string{"config.json", "config.toml"}
+
+// File is a parsed config file.
+type File struct {
+	Path string
+	// Values maps flattened keys to values in flag syntax.
+	Values map[string]string
+	// Keys maps action names to key specs from the "keys" table.
+	Keys map[string]string
+}
+
+// keysPrefix is the prefix of the flattened keys of the "keys" table.
+const keysPrefix = "keys."
+
+// Dir returns the directory holding the configuration and presets.
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "glitch-saver"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "glitch-saver"), nil
+}
+
+// Load reads the config file in dir. It returns nil and no error if there is
+// none, and an error if there is more than one.
+func Load(dir string) (*File, error) {
+	var found []string
+	for _, name := range Names {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("found both %s and %s, remove one of them", found[0], found[1])
+	}
+
+	data, err := os.ReadFile(found[0])
+	if err != nil {
+		return nil, err
+	}
+	values, err := Parse(found[0], data)
+	if err != nil {
+		return nil, err
+	}
+	file := &File{Path: found[0], Values: values, Keys: make(map[string]string)}
+	for k, v := range values {
+		if action, ok := strings.CutPrefix(k, keysPrefix); ok {
+			file.Keys[action] = v
+			delete(values, k)
+		}
+	}
+	return file, nil
+}
+
+// Parse decodes a config file in the format given by the extension of name,
+// ".json" or ".toml".
+func Parse(name string, data []byte) (map[string]string, error) {
+	var (
+		values map[string]string
+		err    error
+	)
+	switch ext := filepath.Ext(name); ext {
+	case ".json":
+		values, err = parseJSON(data)
+	case ".toml":
+		values, err = parseTOML(data)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config format %q", name, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return values, nil
+}
+
+func parseJSON(data []byte) (map[string]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var root map[string]any
+	if err := dec.Decode(&root); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := flattenJSON(values, "", root); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flattenJSON(values map[string]string, prefix string, obj map[string]any) error {
+	for k, v := range obj {
+		key := prefix + k
+		switch v := v.(type) {
+		case map[string]any:
+			if err := flattenJSON(values, key+".", v); err != nil {
+				return err
+			}
+		case bool:
+			values[key] = strconv.FormatBool(v)
+		case json.Number:
+			values[key] = v.String()
+		case string:
+			values[key] = v
+		default:
+			return fmt.Errorf("%s: expected a bool, number, string or object", key)
+		}
+	}
+	return nil
+}
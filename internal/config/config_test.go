@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	data := []byte(`# baseline
+fps = 20
+melt = true # trailing comment
+scroll-direction = "vertical"
+static-char = '#'
+melt-prob = 0.25
+big = 1_000
+
+[keys]
+"pause" = "space"
+`)
+	got, err := Parse("config.toml", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"fps":              "20",
+		"melt":             "true",
+		"scroll-direction": "vertical",
+		"static-char":      "#",
+		"melt-prob":        "0.25",
+		"big":              "1000",
+		"keys.pause":       "space",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse = %v, want %v", got, want)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, data := range []string{
+		"fps",
+		"fps = ",
+		"fps = [1, 2]",
+		"fps = 1\nfps = 2",
+		"name = \"unterminated",
+		"[table",
+		"bad key = 1",
+	} {
+		if _, err := Parse("config.toml", []byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	got, err := Parse("config.json", []byte(`{"fps": 20, "melt": true, "keys": {"pause": "space"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"fps": "20", "melt": "true", "keys.pause": "space"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse = %v, want %v", got, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if f, err := Load(dir); f != nil || err != nil {
+		t.Fatalf("Load(empty dir) = %v, %v, want nil, nil", f, err)
+	}
+
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte("fps = 10\n[keys]\npause = \"p\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Path != path || !reflect.DeepEqual(f.Values, map[string]string{"fps": "10"}) || !reflect.DeepEqual(f.Keys, map[string]string{"pause": "p"}) {
+		t.Errorf("Load = %+v", f)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("Load with both config.json and config.toml succeeded, want an error")
+	}
+}
+
+func TestDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if dir, err := Dir(); err != nil || dir != filepath.Join("/xdg", "glitch-saver") {
+		t.Errorf("Dir() = %q, %v", dir, err)
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML decodes the subset of TOML a config file needs: comments, tables
+// and key/value pairs whose values are strings, booleans or numbers.
+func parseTOML(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	prefix := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header %q", lineNo, line)
+			}
+			name, err := parseKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			prefix = name + "."
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key, err := parseKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		value, err := parseValue(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", lineNo, key, err)
+		}
+		if _, dup := values[prefix+key]; dup {
+			return nil, fmt.Errorf("line %d: %s is defined twice", lineNo, prefix+key)
+		}
+		values[prefix+key] = value
+	}
+	return values, nil
+}
+
+// stripComment removes a trailing # comment that is not inside a string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseKey parses a possibly dotted key made of bare or quoted parts.
+func parseKey(s string) (string, error) {
+	var parts []string
+	for _, part := range strings.Split(s, ".") {
+		part = strings.TrimSpace(part)
+		if len(part) >= 2 && (part[0] == '"' || part[0] == '\'') && part[len(part)-1] == part[0] {
+			parts = append(parts, part[1:len(part)-1])
+			continue
+		}
+		if part == "" || strings.IndexFunc(part, func(r rune) bool {
+			return !(r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+		}) >= 0 {
+			return "", fmt.Errorf("invalid key %q", s)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// parseValue converts a TOML value to flag syntax.
+func parseValue(s string) (string, error) {
+	switch {
+	case s == "":
+		return "", fmt.Errorf("missing value")
+	case s == "true" || s == "false":
+		return s, nil
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") || strings.Contains(s[1:len(s)-1], "'") {
+			return "", fmt.Errorf("invalid string %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	case strings.HasPrefix(s, "["), strings.HasPrefix(s, "{"):
+		return "", fmt.Errorf("arrays and inline tables are not supported")
+	}
+
+	n := strings.ReplaceAll(s, "_", "")
+	if _, err := strconv.ParseInt(n, 0, 64); err == nil {
+		return n, nil
+	}
+	if _, err := strconv.ParseFloat(n, 64); err == nil {
+		return n, nil
+	}
+	return "", fmt.Errorf("invalid value %s", s)
+}
@@ -1,33 +1,40 @@
 package effects
 
 import (
+	"glitch-saver/internal/charset"
+	"glitch-saver/internal/frame"
 	"glitch-saver/internal/options"
+	"glitch-saver/internal/theme"
 	"math/rand"
+	"slices"
 
 	"github.com/gdamore/tcell/v2"
 )
 
-const glitchChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!@#$%^&*()_+-=[]{}|;':\",./<>?`~ "
-const cp437Chars = "ÇüéâäàåçêëèïîìÄÅÉæÆôöòûùÿÖÜ¢£¥₧ƒáíóúñÑªº¿⌐¬½¼¡«»░▒▓│┤╡╢╖╕╣║╗╝╜⛛┐└┴┬├─┼╞╟╚╔╩╦╠═╬╧╨╤╥╙╘╒╓╫╪┘┌█▄▌▐▀αßΓπΣσµτΦΘΩδ∞φε∩≡±≥≤⌠⌡÷≈°∙·√ⁿ²■ "
-const blockChars = "░▒▓█"
 const staticChars = " .*"
 
-// Using NewRGBColor for explicit color definitions
-var glitchColors = []tcell.Color{
-	tcell.NewRGBColor(0, 0, 0),       // Black
-	tcell.NewRGBColor(255, 0, 0),     // Red
-	tcell.NewRGBColor(0, 255, 0),     // Green
-	tcell.NewRGBColor(255, 255, 0),   // Yellow
-	tcell.NewRGBColor(0, 0, 255),     // Blue
-	tcell.NewRGBColor(255, 0, 255),   // Magenta
-	tcell.NewRGBColor(0, 255, 255),   // Cyan
-	tcell.NewRGBColor(255, 255, 255), // White
+// monoAttrs stand in for foreground colors in mono mode.
+var monoAttrs = []tcell.AttrMask{tcell.AttrNone, tcell.AttrBold, tcell.AttrDim, tcell.AttrUnderline}
+
+// monoCycle is the sequence of attributes color-cycling cells step through in
+// mono mode.
+var monoCycle = []tcell.AttrMask{
+	tcell.AttrNone,
+	tcell.AttrBold,
+	tcell.AttrBold | tcell.AttrUnderline,
+	tcell.AttrReverse,
+	tcell.AttrReverse | tcell.AttrBold,
+	tcell.AttrDim,
 }
 
-var staticColors = []tcell.Color{
-	tcell.NewRGBColor(0, 0, 0),       // Black
-	tcell.NewRGBColor(128, 128, 128), // Grey
-	tcell.NewRGBColor(255, 255, 255), // White
+// monoCell returns a cell showing r with random attributes instead of colors.
+// With useBG, half of the cells are reversed in place of a background color.
+func monoCell(r rune, rGen *rand.Rand, useBG bool) frame.Cell {
+	c := frame.Cell{Rune: r, Attrs: monoAttrs[rGen.Intn(len(monoAttrs))]}
+	if useBG && rGen.Intn(2) == 0 {
+		c.Attrs |= tcell.AttrReverse
+	}
+	return c
 }
 
 // Point represents a coordinate on the screen.
@@ -35,108 +42,76 @@ type Point struct {
 	X, Y int
 }
 
-// cyclingCells holds the state of cells that are cycling colors.
-var cyclingCells = make(map[Point]int)
-
 // SmearCell represents a cell with a trail life.
 type SmearCell struct {
-	r        rune
-	style    tcell.Style
+	cell     frame.Cell
 	lifetime int
 }
 
-var smearBuffer [][]SmearCell
-var ghostBuffer [][]SmearCell
-
-// staticFrames tracks the remaining duration of a static burst.
-var staticFrames int
-
 // ScrollingBlock represents a block of the screen that is scrolling.
 type ScrollingBlock struct {
 	srcX, srcY, destX, destY, w, h, dx, dy, life int
-	cells                                        [][]SmearCell
-}
-
-var scrollingBlocks []*ScrollingBlock
-
-func InitializeEffects(width, height int) {
-	smearBuffer = make([][]SmearCell, height)
-	ghostBuffer = make([][]SmearCell, height)
-	for i := range smearBuffer {
-		smearBuffer[i] = make([]SmearCell, width)
-		ghostBuffer[i] = make([]SmearCell, width)
-	}
-	scrollingBlocks = nil
-	cyclingCells = make(map[Point]int)
+	cells                                        [][]frame.Cell
 }
 
 // shiftLineGlitch shifts a random line horizontally
-func shiftLineGlitch(s tcell.Screen, width, height int, rGen *rand.Rand) { // opts added
-	if height == 0 || width == 0 {
+func shiftLineGlitch(g *frame.Grid, width, height int, rGen *rand.Rand) { // opts added
+	if height == 0 || width < 2 {
 		return
 	}
 	y := rGen.Intn(height)
 	offset := rGen.Intn(width/2) - (width / 4)
 
-	line := make([]struct {
-		r     rune
-		style tcell.Style
-	}, width)
+	line := make([]frame.Cell, width)
 
 	for x := 0; x < width; x++ {
 		// Bounds checking to prevent access beyond screen dimensions
 		if y >= 0 && y < height && x >= 0 && x < width {
-			mainc, style, _ := s.Get(x, y)
-			line[x].r = rune(mainc[0])
-			line[x].style = style
+			line[x] = g.Get(x, y)
 		}
 	}
 
 	for x := 0; x < width; x++ {
 		newX := x + offset
 		if newX >= 0 && newX < width && x >= 0 && x < width {
-			if line[x].r != 0 { // Only draw if the buffered rune is not a zero value
-				s.SetContent(newX, y, line[x].r, nil, line[x].style)
+			if !line[x].IsContinuation() { // Continuation cells are drawn with their wide character
+				g.Set(newX, y, line[x])
 			}
 		}
 	}
 }
 
 // applyVerticalLineGlitch shifts a random column vertically
-func applyVerticalLineGlitch(s tcell.Screen, width, height int, rGen *rand.Rand) {
-	if width == 0 || height == 0 {
+func applyVerticalLineGlitch(g *frame.Grid, width, height int, rGen *rand.Rand) {
+	if width == 0 || height < 2 {
 		return
 	}
 	x := rGen.Intn(width)
 	offset := rGen.Intn(height/2) - (height / 4)
 
-	column := make([]struct {
-		r     rune
-		style tcell.Style
-	}, height)
+	column := make([]frame.Cell, height)
 
 	for y := 0; y < height; y++ {
 		// Bounds checking to prevent access beyond screen dimensions
 		if y >= 0 && y < height && x >= 0 && x < width {
-			mainc, style, _ := s.Get(x, y)
-			column[y].r = rune(mainc[0])
-			column[y].style = style
+			column[y] = g.Get(x, y)
 		}
 	}
 
 	for y := 0; y < height; y++ {
 		newY := y + offset
 		if newY >= 0 && newY < height && x >= 0 && x < width {
-			if column[y].r != 0 {
-				s.SetContent(x, newY, column[y].r, nil, column[y].style)
+			if !column[y].IsContinuation() {
+				g.Set(x, newY, column[y])
 			}
 		}
 	}
 }
 
 // applyInvertColorsGlitch inverts the colors of a random block of the screen
-func applyInvertColorsGlitch(s tcell.Screen, width, height int, rGen *rand.Rand) {
-	if width == 0 || height == 0 {
+// In mono mode it toggles reverse video instead.
+func applyInvertColorsGlitch(g *frame.Grid, width, height int, rGen *rand.Rand, mono bool) {
+	if width < 2 || height < 2 {
 		return
 	}
 	blockX := rGen.Intn(width)
@@ -154,17 +129,20 @@ func applyInvertColorsGlitch(s tcell.Screen, width, height int, rGen *rand.Rand)
 
 	for y := blockY; y < blockY+blockH && y < height; y++ {
 		for x := blockX; x < blockX+blockW && x < width; x++ {
-			mainc, style, _ := s.Get(x, y)
-			fg, bg, _ := style.Decompose()
-			newStyle := style.Foreground(bg).Background(fg)
-			s.SetContent(x, y, rune(mainc[0]), nil, newStyle)
+			c := g.Get(x, y)
+			if mono {
+				c.Attrs ^= tcell.AttrReverse
+			} else {
+				c.Fg, c.Bg = c.Bg, c.Fg
+			}
+			g.Set(x, y, c)
 		}
 	}
 }
 
 // applyCharScrambleGlitch scrambles the characters in a random block of the screen
-func applyCharScrambleGlitch(s tcell.Screen, width, height int, rGen *rand.Rand) {
-	if width == 0 || height == 0 {
+func applyCharScrambleGlitch(g *frame.Grid, width, height int, rGen *rand.Rand) {
+	if width < 4 || height < 4 {
 		return
 	}
 	blockX := rGen.Intn(width)
@@ -181,51 +159,48 @@ func applyCharScrambleGlitch(s tcell.Screen, width, height int, rGen *rand.Rand)
 	}
 
 	// Read the block's content
-	cells := make([][]struct {
-		r     rune
-		style tcell.Style
-	}, blockH)
+	cells := make([][]frame.Cell, blockH)
 	for y := 0; y < blockH; y++ {
-		cells[y] = make([]struct {
-			r     rune
-			style tcell.Style
-		}, blockW)
+		cells[y] = make([]frame.Cell, blockW)
 		for x := 0; x < blockW; x++ {
 			if blockX+x < width && blockY+y < height {
-				mainc, style, _ := s.Get(blockX+x, blockY+y)
-				cells[y][x].r = rune(mainc[0])
-				cells[y][x].style = style
+				cells[y][x] = g.Get(blockX+x, blockY+y)
 			}
 		}
 	}
 
-	// Flatten, shuffle, and rewrite the characters
-	runes := make([]rune, 0, blockW*blockH)
-	for _, row := range cells {
-		for _, cell := range row {
-			runes = append(runes, cell.r)
-		}
-	}
-	rGen.Shuffle(len(runes), func(i, j int) {
-		runes[i], runes[j] = runes[j], runes[i]
-	})
-
-	// Rewrite the scrambled characters
-	i := 0
+	// Shuffle the characters among the cells of the same width, so that wide
+	// characters keep both of their columns. Continuation cells move with
+	// their character.
+	var narrow, wide []Point
 	for y := 0; y < blockH; y++ {
 		for x := 0; x < blockW; x++ {
-			if blockX+x < width && blockY+y < height {
-				if i < len(runes) {
-					s.SetContent(blockX+x, blockY+y, runes[i], nil, cells[y][x].style)
-					i++
-				}
+			switch cells[y][x].Width() {
+			case 1:
+				narrow = append(narrow, Point{x, y})
+			case 2:
+				wide = append(wide, Point{x, y})
 			}
 		}
 	}
+	for _, points := range [][]Point{narrow, wide} {
+		from := slices.Clone(points)
+		rGen.Shuffle(len(from), func(i, j int) {
+			from[i], from[j] = from[j], from[i]
+		})
+
+		// Rewrite the scrambled characters in the style of their new cell
+		for i, p := range points {
+			src := cells[from[i].Y][from[i].X]
+			c := cells[p.Y][p.X]
+			c.Rune, c.Combining = src.Rune, src.Combining
+			g.Set(blockX+p.X, blockY+p.Y, c)
+		}
+	}
 }
 
 // applyTunnelEffect creates a zoom/tunnel effect by shifting characters
-func applyTunnelEffect(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
+func applyTunnelEffect(g *frame.Grid, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
 	if !opts.TunnelEnable {
 		return
 	}
@@ -237,36 +212,22 @@ func applyTunnelEffect(s tcell.Screen, width, height int, rGen *rand.Rand, opts
 	centerY := height / 2
 
 	// Create a temporary buffer to hold the original screen state for this effect
-	originalScreen := make([][]struct {
-		r     rune
-		style tcell.Style
-	}, height)
+	originalScreen := make([][]frame.Cell, height)
 	for i := range originalScreen {
-		originalScreen[i] = make([]struct {
-			r     rune
-			style tcell.Style
-		}, width)
+		originalScreen[i] = make([]frame.Cell, width)
 	}
 
 	// Read the current screen content first
 	for y := 0; y < height; y++ {
 		for x := 0; x < width; x++ {
-			mainc, style, _ := s.Get(x, y)
-			originalScreen[y][x].r = rune(mainc[0])
-			originalScreen[y][x].style = style
+			originalScreen[y][x] = g.Get(x, y)
 		}
 	}
 
 	// Create a temporary buffer to hold the new screen state
-	newScreen := make([][]struct {
-		r     rune
-		style tcell.Style
-	}, height)
+	newScreen := make([][]frame.Cell, height)
 	for i := range newScreen {
-		newScreen[i] = make([]struct {
-			r     rune
-			style tcell.Style
-		}, width)
+		newScreen[i] = make([]frame.Cell, width)
 	}
 
 	for y := 0; y < height; y++ {
@@ -281,8 +242,7 @@ func applyTunnelEffect(s tcell.Screen, width, height int, rGen *rand.Rand, opts
 			if srcX >= 0 && srcX < width && srcY >= 0 && srcY < height {
 				newScreen[y][x] = originalScreen[srcY][srcX]
 			} else {
-				newScreen[y][x].r = ' '
-				newScreen[y][x].style = tcell.StyleDefault
+				newScreen[y][x] = frame.Blank
 			}
 		}
 	}
@@ -290,14 +250,14 @@ func applyTunnelEffect(s tcell.Screen, width, height int, rGen *rand.Rand, opts
 	// Apply the new screen state
 	for y := 0; y < height; y++ {
 		for x := 0; x < width; x++ {
-			s.SetContent(x, y, newScreen[y][x].r, nil, newScreen[y][x].style)
+			g.Set(x, y, newScreen[y][x])
 		}
 	}
 }
 
 // blockDistortionGlitch copies a random block of the screen to another random location
-func blockDistortionGlitch(s tcell.Screen, width, height int, rGen *rand.Rand) { // opts added
-	if width == 0 || height == 0 {
+func blockDistortionGlitch(g *frame.Grid, width, height int, rGen *rand.Rand) { // opts added
+	if width < 2 || height < 2 {
 		return
 	}
 	srcX, srcY := rGen.Intn(width), rGen.Intn(height)
@@ -322,21 +282,13 @@ func blockDistortionGlitch(s tcell.Screen, width, height int, rGen *rand.Rand) {
 		blockH = height - destY
 	}
 
-	block := make([][]struct {
-		r     rune
-		style tcell.Style
-	}, blockH)
+	block := make([][]frame.Cell, blockH)
 
 	for y := 0; y < blockH; y++ {
-		block[y] = make([]struct {
-			r     rune
-			style tcell.Style
-		}, blockW)
+		block[y] = make([]frame.Cell, blockW)
 		for x := 0; x < blockW; x++ {
 			if srcX+x < width && srcY+y < height {
-				mainc, style, _ := s.Get(srcX+x, srcY+y)
-				block[y][x].r = rune(mainc[0])
-				block[y][x].style = style
+				block[y][x] = g.Get(srcX+x, srcY+y)
 			}
 		}
 	}
@@ -344,8 +296,8 @@ func blockDistortionGlitch(s tcell.Screen, width, height int, rGen *rand.Rand) {
 	for y := 0; y < blockH; y++ {
 		for x := 0; x < blockW; x++ {
 			if destX+x < width && destY+y < height {
-				if block[y][x].r != 0 { // Only draw if the buffered rune is not a zero value
-					s.SetContent(destX+x, destY+y, block[y][x].r, nil, block[y][x].style)
+				if !block[y][x].IsContinuation() { // Continuation cells are drawn with their wide character
+					g.Set(destX+x, destY+y, block[y][x])
 				}
 			}
 		}
@@ -353,50 +305,52 @@ func blockDistortionGlitch(s tcell.Screen, width, height int, rGen *rand.Rand) {
 }
 
 // applyCharCorruption draws random characters with glitch effects to the screen.
-func applyCharCorruption(s tcell.Screen, width, height int, rGen *rand.Rand, charSet []rune, fgColors []tcell.Color, opts *options.GlitchOptions, bgColors []tcell.Color) {
+func (e *Engine) applyCharCorruption(g *frame.Grid, width, height int, rGen *rand.Rand, charSet *charset.Set, fgColors []tcell.Color, opts *options.GlitchOptions, bgColors []tcell.Color, cycleColors []tcell.Color, mono bool) {
 	numGlitch := rGen.Intn(100*opts.Intensity) + (50 * opts.Intensity)
 	for i := 0; i < numGlitch; i++ {
 		x := rGen.Intn(width)
 		y := rGen.Intn(height)
 
-		r := charSet[rGen.Intn(len(charSet))]
-		fg := fgColors[rGen.Intn(len(fgColors))]
-
-		style := tcell.StyleDefault.Foreground(fg)
+		r := charSet.Pick(rGen)
 
-		if opts.UseBG {
-			bg := bgColors[rGen.Intn(len(bgColors))]
-			style = style.Background(bg)
+		var c frame.Cell
+		if mono {
+			c = monoCell(r, rGen, opts.UseBG)
+		} else {
+			c = frame.Cell{Rune: r, Fg: fgColors[rGen.Intn(len(fgColors))]}
+			if opts.UseBG {
+				c.Bg = bgColors[rGen.Intn(len(bgColors))]
+			}
 		}
 
-		s.SetContent(x, y, r, nil, style)
+		g.Set(x, y, c)
 
 		// Add to color cycling
 		if opts.ColorCycleEnable {
 			if rGen.Float64() < 0.1 { // 10% chance to add to cycling
-				cyclingCells[Point{x, y}] = rGen.Intn(len(glitchColors))
+				e.cyclingCells[Point{x, y}] = rGen.Intn(len(cycleColors))
 			}
 		}
 
 		// Add to smear buffer
 		if opts.SmearEnable {
 			if rGen.Float64() < opts.SmearProbability {
-				smearBuffer[y][x] = SmearCell{r, style, opts.SmearLength}
+				e.smearBuffer[y][x] = SmearCell{c, opts.SmearLength}
 			}
 		}
 
 		// Add to ghost buffer
 		if opts.GhostingEnable {
 			if rGen.Float64() < opts.GhostingProbability {
-				ghostBuffer[y][x] = SmearCell{r, style, 10} // 10 frames lifetime for ghost
+				e.ghostBuffer[y][x] = SmearCell{c, 10} // 10 frames lifetime for ghost
 			}
 		}
 	}
 }
 
 // applyScanlineEffect draws a horizontal scanline with glitch effects.
-func applyScanlineEffect(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
-	if height == 0 || !opts.ScanlineEnable {
+func applyScanlineEffect(g *frame.Grid, width, height int, rGen *rand.Rand, opts *options.GlitchOptions, charSet *charset.Set, t *theme.Theme, mono bool) {
+	if height == 0 || width < 2 || !opts.ScanlineEnable {
 		return
 	}
 	if rGen.Float64() > opts.ScanlineProbability { // Check probability
@@ -405,13 +359,8 @@ func applyScanlineEffect(s tcell.Screen, width, height int, rGen *rand.Rand, opt
 
 	y := rGen.Intn(height) // Random row
 
-	scanlineRunes := []rune(glitchChars)
 	if opts.ScanlineChar != "" {
-		scanlineRunes = []rune(opts.ScanlineChar)
-	} else if opts.UseBlocks {
-		scanlineRunes = []rune(blockChars)
-	} else if opts.UseCP437 {
-		scanlineRunes = []rune(cp437Chars)
+		charSet = charset.New([]rune(opts.ScanlineChar))
 	}
 
 	numScanlineChars := width / 2 // Default density
@@ -425,79 +374,86 @@ func applyScanlineEffect(s tcell.Screen, width, height int, rGen *rand.Rand, opt
 	for i := 0; i < numScanlineChars; i++ {
 		x := rGen.Intn(width) // Random position within the row
 
-		r := scanlineRunes[rGen.Intn(len(scanlineRunes))]
-		fg := glitchColors[rGen.Intn(len(glitchColors))]
+		r := charSet.Pick(rGen)
 
-		style := tcell.StyleDefault.Foreground(fg)
-		if opts.UseBG {
-			bg := glitchColors[rGen.Intn(len(glitchColors))]
-			style = style.Background(bg)
+		var c frame.Cell
+		if mono {
+			c = monoCell(r, rGen, opts.UseBG)
+		} else {
+			c = frame.Cell{Rune: r, Fg: t.Fg[rGen.Intn(len(t.Fg))]}
+			if opts.UseBG {
+				c.Bg = t.Bg[rGen.Intn(len(t.Bg))]
+			}
 		}
 
-		s.SetContent(x, y, r, nil, style)
+		g.Set(x, y, c)
 	}
 }
 
 // applyColorCycle updates the colors of cycling cells.
-func applyColorCycle(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
+// In mono mode the cells step through attributes instead.
+func (e *Engine) applyColorCycle(g *frame.Grid, width, height int, rGen *rand.Rand, opts *options.GlitchOptions, cycleColors []tcell.Color, mono bool) {
 	if !opts.ColorCycleEnable {
 		return
 	}
 
 	// Clean up out-of-bounds positions first
-	for p := range cyclingCells {
+	for p := range e.cyclingCells {
 		if p.X < 0 || p.X >= width || p.Y < 0 || p.Y >= height {
-			delete(cyclingCells, p)
+			delete(e.cyclingCells, p)
 		}
 	}
 
-	for p, colorIndex := range cyclingCells {
+	for p, colorIndex := range e.cyclingCells {
 		// Double-check bounds after potential cleanup
 		if p.X < 0 || p.X >= width || p.Y < 0 || p.Y >= height {
-			delete(cyclingCells, p)
+			delete(e.cyclingCells, p)
+			continue
+		}
+
+		c := g.Get(p.X, p.Y)
+		if c.Rune == ' ' {
+			delete(e.cyclingCells, p)
 			continue
 		}
 
-		mainc, style, _ := s.Get(p.X, p.Y)
-		if rune(mainc[0]) == ' ' {
-			delete(cyclingCells, p)
+		if mono {
+			colorIndex = (colorIndex + 1) % len(monoCycle)
+			e.cyclingCells[p] = colorIndex
+			c.Attrs = monoCycle[colorIndex]
+			g.Set(p.X, p.Y, c)
 			continue
 		}
 
 		// Update color index
-		colorIndex = (colorIndex + opts.ColorCycleSpeed) % len(glitchColors)
-		cyclingCells[p] = colorIndex
+		colorIndex = (colorIndex + opts.ColorCycleSpeed) % len(cycleColors)
+		e.cyclingCells[p] = colorIndex
 
-		newStyle := style.Foreground(glitchColors[colorIndex])
+		c.Fg = cycleColors[colorIndex]
 
 		if opts.UseBG {
-			bg := glitchColors[(colorIndex+len(glitchColors)/2)%len(glitchColors)] // Offset background color
-			newStyle = newStyle.Background(bg)
+			c.Bg = cycleColors[(colorIndex+len(cycleColors)/2)%len(cycleColors)] // Offset background color
 		}
 
-		s.SetContent(p.X, p.Y, rune(mainc[0]), nil, newStyle)
+		g.Set(p.X, p.Y, c)
 	}
 }
 
 // applySmear draws and fades smeared characters.
-func applySmear(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
+func (e *Engine) applySmear(g *frame.Grid, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
 	if !opts.SmearEnable {
 		return
 	}
 
-	// Ensure buffer dimensions match screen dimensions to prevent out-of-bounds access
-	if len(smearBuffer) != height {
-		// Reinitialize smearBuffer if dimensions don't match
-		InitializeEffects(width, height)
-	}
-
-	for y := 0; y < height && y < len(smearBuffer); y++ {
-		for x := 0; x < width && x < len(smearBuffer[y]); x++ {
-			if smearBuffer[y][x].lifetime > 0 {
-				smearBuffer[y][x].lifetime--
-				s.SetContent(x, y, smearBuffer[y][x].r, nil, smearBuffer[y][x].style.Dim(true))
-				if smearBuffer[y][x].lifetime == 0 {
-					s.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+	for y := 0; y < height && y < len(e.smearBuffer); y++ {
+		for x := 0; x < width && x < len(e.smearBuffer[y]); x++ {
+			if e.smearBuffer[y][x].lifetime > 0 {
+				e.smearBuffer[y][x].lifetime--
+				c := e.smearBuffer[y][x].cell
+				c.Attrs |= tcell.AttrDim
+				g.Set(x, y, c)
+				if e.smearBuffer[y][x].lifetime == 0 {
+					g.Set(x, y, frame.Blank)
 				}
 			}
 		}
@@ -505,36 +461,56 @@ func applySmear(s tcell.Screen, width, height int, rGen *rand.Rand, opts *option
 }
 
 // applyGhostingEffect draws and fades ghosted characters.
-func applyGhostingEffect(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
+func (e *Engine) applyGhostingEffect(g *frame.Grid, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
 	if !opts.GhostingEnable {
 		return
 	}
 
-	// Ensure buffer dimensions match screen dimensions to prevent out-of-bounds access
-	if len(ghostBuffer) != height {
-		// Reinitialize ghostBuffer if dimensions don't match
-		InitializeEffects(width, height)
-	}
-
-	for y := 0; y < height && y < len(ghostBuffer); y++ {
-		for x := 0; x < width && x < len(ghostBuffer[y]); x++ {
-			if ghostBuffer[y][x].lifetime > 0 {
-				ghostBuffer[y][x].lifetime--
+	for y := 0; y < height && y < len(e.ghostBuffer); y++ {
+		for x := 0; x < width && x < len(e.ghostBuffer[y]); x++ {
+			if e.ghostBuffer[y][x].lifetime > 0 {
+				e.ghostBuffer[y][x].lifetime--
 				// Draw the ghost with a dimmer style
-				fg, bg, _ := ghostBuffer[y][x].style.Decompose()
-				ghostStyle := tcell.StyleDefault.Foreground(fg).Background(bg).Dim(true)
-				s.SetContent(x, y, ghostBuffer[y][x].r, nil, ghostStyle)
+				ghost := e.ghostBuffer[y][x].cell
+				g.Set(x, y, frame.Cell{Rune: ghost.Rune, Fg: ghost.Fg, Bg: ghost.Bg, Attrs: tcell.AttrDim})
 
-				if ghostBuffer[y][x].lifetime == 0 {
-					s.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+				if e.ghostBuffer[y][x].lifetime == 0 {
+					g.Set(x, y, frame.Blank)
 				}
 			}
 		}
 	}
 }
 
+// staticEffect fills the screen with noise for a few frames at a time and
+// suppresses every other effect while a burst is running.
+type staticEffect struct {
+	frames int // remaining duration of the current burst
+}
+
+func (e *staticEffect) Name() string            { return "static" }
+func (e *staticEffect) Params() []options.Param { return nil }
+func (e *staticEffect) Init(width, height int)  {}
+
+func (e *staticEffect) Enabled(opts *options.GlitchOptions) bool {
+	return opts.StaticEnable || e.frames > 0
+}
+
+func (e *staticEffect) Apply(ctx *Context) {
+	if e.frames > 0 {
+		applyStaticBurst(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Opts, ctx.Theme.Static, ctx.Mono)
+		e.frames--
+		ctx.Stop()
+		return
+	}
+	if ctx.Opts.StaticEnable && ctx.Rand.Float64() < ctx.Opts.StaticProbability {
+		e.frames = ctx.Opts.StaticDuration
+		ctx.Stop()
+	}
+}
+
 // applyStaticBurst fills the screen with static noise.
-func applyStaticBurst(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
+func applyStaticBurst(g *frame.Grid, width, height int, rGen *rand.Rand, opts *options.GlitchOptions, staticColors []tcell.Color, mono bool) {
 	staticRunes := []rune(staticChars)
 	if opts.StaticChar != "" {
 		staticRunes = []rune(opts.StaticChar)
@@ -546,23 +522,40 @@ func applyStaticBurst(s tcell.Screen, width, height int, rGen *rand.Rand, opts *
 		y := rGen.Intn(height)
 
 		r := staticRunes[rGen.Intn(len(staticRunes))]
+		if mono {
+			g.Set(x, y, monoCell(r, rGen, true))
+			continue
+		}
 		fg := staticColors[rGen.Intn(len(staticColors))]
 		bg := staticColors[rGen.Intn(len(staticColors))]
 
-		style := tcell.StyleDefault.Foreground(fg).Background(bg)
-		s.SetContent(x, y, r, nil, style)
+		g.Set(x, y, frame.Cell{Rune: r, Fg: fg, Bg: bg})
 	}
 }
 
+// scrollEffect scrolls copied blocks of the screen across it.
+type scrollEffect struct {
+	blocks []*ScrollingBlock
+}
+
+func (e *scrollEffect) Name() string                             { return "scroll" }
+func (e *scrollEffect) Params() []options.Param                  { return nil }
+func (e *scrollEffect) Enabled(opts *options.GlitchOptions) bool { return opts.ScrollEnable }
+func (e *scrollEffect) Init(width, height int)                   { e.blocks = nil }
+
+func (e *scrollEffect) Apply(ctx *Context) {
+	e.applyScrollingBlocks(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Opts)
+}
+
 // applyScrollingBlocks scrolls blocks of the screen.
-func applyScrollingBlocks(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
+func (e *scrollEffect) applyScrollingBlocks(g *frame.Grid, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
 	if !opts.ScrollEnable {
 		return
 	}
 
 	// Remove dead blocks and blocks that would be out of bounds after resize
-	newScrollingBlocks := scrollingBlocks[:0]
-	for _, b := range scrollingBlocks {
+	newScrollingBlocks := e.blocks[:0]
+	for _, b := range e.blocks {
 		if b.life > 0 {
 			// Remove blocks that would be outside the screen after resize
 			if b.destX < 0 || b.destY < 0 || b.destX+b.w > width || b.destY+b.h > height {
@@ -574,10 +567,10 @@ func applyScrollingBlocks(s tcell.Screen, width, height int, rGen *rand.Rand, op
 			newScrollingBlocks = append(newScrollingBlocks, b)
 		}
 	}
-	scrollingBlocks = newScrollingBlocks
+	e.blocks = newScrollingBlocks
 
 	// Update and draw existing blocks
-	for _, b := range scrollingBlocks {
+	for _, b := range e.blocks {
 		b.life--
 		b.destX += b.dx
 		b.destY += b.dy
@@ -585,14 +578,14 @@ func applyScrollingBlocks(s tcell.Screen, width, height int, rGen *rand.Rand, op
 		for y := 0; y < b.h; y++ {
 			for x := 0; x < b.w; x++ {
 				if b.destX+x < width && b.destY+y < height && b.destX+x >= 0 && b.destY+y >= 0 {
-					s.SetContent(b.destX+x, b.destY+y, b.cells[y][x].r, nil, b.cells[y][x].style)
+					g.Set(b.destX+x, b.destY+y, b.cells[y][x])
 				}
 			}
 		}
 	}
 
 	// Trigger new blocks
-	if rGen.Float64() < opts.ScrollProbability {
+	if width >= 4 && height >= 4 && rGen.Float64() < opts.ScrollProbability {
 		srcX, srcY := rGen.Intn(width), rGen.Intn(height)
 		blockW := rGen.Intn(width/4) + 5
 		blockH := rGen.Intn(height/4) + 5
@@ -604,12 +597,11 @@ func applyScrollingBlocks(s tcell.Screen, width, height int, rGen *rand.Rand, op
 			blockH = height - srcY
 		}
 
-		cells := make([][]SmearCell, blockH)
+		cells := make([][]frame.Cell, blockH)
 		for y := 0; y < blockH; y++ {
-			cells[y] = make([]SmearCell, blockW)
+			cells[y] = make([]frame.Cell, blockW)
 			for x := 0; x < blockW; x++ {
-				mainc, style, _ := s.Get(srcX+x, srcY+y)
-				cells[y][x] = SmearCell{rune(mainc[0]), style, 1}
+				cells[y][x] = g.Get(srcX+x, srcY+y)
 			}
 		}
 
@@ -633,7 +625,7 @@ func applyScrollingBlocks(s tcell.Screen, width, height int, rGen *rand.Rand, op
 			dx = 1 // Ensure movement
 		}
 
-		scrollingBlocks = append(scrollingBlocks, &ScrollingBlock{
+		e.blocks = append(e.blocks, &ScrollingBlock{
 			srcX:  srcX,
 			srcY:  srcY,
 			destX: srcX,
@@ -648,71 +640,98 @@ func applyScrollingBlocks(s tcell.Screen, width, height int, rGen *rand.Rand, op
 	}
 }
 
-// DrawGlitch orchestrates various glitch effects on the screen.
-func DrawGlitch(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) { // opts replaces many args
-	if staticFrames > 0 {
-		applyStaticBurst(s, width, height, rGen, opts)
-		staticFrames--
-		return
-	}
-	if opts.StaticEnable && rGen.Float64() < opts.StaticProbability {
-		staticFrames = opts.StaticDuration
-		return
-	}
-
-	var charSet []rune
-	if opts.UseBlocks {
-		charSet = []rune(blockChars)
-	} else if opts.UseCP437 {
-		charSet = []rune(cp437Chars)
-	} else {
-		charSet = []rune(glitchChars)
-	}
-
-	// If both UseBlocks and UseCP437 are enabled, combine the character sets
-	if opts.UseBlocks && opts.UseCP437 {
-		charSet = append([]rune(blockChars), []rune(cp437Chars)...)
-	}
-
-	if opts.CharCorruptionEnable {
-		applyCharCorruption(s, width, height, rGen, charSet, glitchColors, opts, glitchColors)
-	}
-
-	if opts.ShiftLineEnable && rGen.Intn(10) < 2 {
-		shiftLineGlitch(s, width, height, rGen)
-	}
-
-	if opts.VerticalLineEnable && rGen.Float64() < opts.VerticalLineProbability {
-		applyVerticalLineGlitch(s, width, height, rGen)
-	}
-
-	if opts.InvertColorsEnable && rGen.Float64() < opts.InvertColorsProbability {
-		applyInvertColorsGlitch(s, width, height, rGen)
-	}
-
-	if opts.CharScrambleEnable && rGen.Float64() < opts.CharScrambleProbability {
-		applyCharScrambleGlitch(s, width, height, rGen)
-	}
-
-	if opts.TunnelEnable && rGen.Float64() < opts.TunnelProbability {
-		applyTunnelEffect(s, width, height, rGen, opts)
-	}
-
-	if opts.BlockDistortionEnable && rGen.Intn(10) < 1 {
-		blockDistortionGlitch(s, width, height, rGen)
-	}
-
-	applyScanlineEffect(s, width, height, rGen, opts)  // Call new scanline effect
-	applyColorCycle(s, width, height, rGen, opts)      // Call new color cycle effect
-	applySmear(s, width, height, rGen, opts)           // Call new smear effect
-	applyGhostingEffect(s, width, height, rGen, opts)  // Call new ghosting effect
-	applyScrollingBlocks(s, width, height, rGen, opts) // Call new scrolling blocks effect
-	applyBitRot(s, width, height, rGen, opts)
-	applyMelt(s, width, height, rGen, opts)
-	applyJitter(s, width, height, rGen, opts)
+func init() {
+	Register(0, func() Effect { return &staticEffect{} })
+	registerFunc(10, "char-corrupt", func(opts *options.GlitchOptions) bool {
+		return opts.CharCorruptionEnable
+	}, func(ctx *Context) {
+		ctx.engine.applyCharCorruption(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.CharSet, ctx.Theme.Fg, ctx.Opts, ctx.Theme.Bg, ctx.Theme.Cycle, ctx.Mono)
+	})
+	registerFunc(20, "shift-line", func(opts *options.GlitchOptions) bool {
+		return opts.ShiftLineEnable
+	}, func(ctx *Context) {
+		if ctx.Rand.Intn(10) < 2 {
+			shiftLineGlitch(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand)
+		}
+	})
+	registerFunc(30, "vert-line", func(opts *options.GlitchOptions) bool {
+		return opts.VerticalLineEnable
+	}, func(ctx *Context) {
+		if ctx.Rand.Float64() < ctx.Opts.VerticalLineProbability {
+			applyVerticalLineGlitch(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand)
+		}
+	})
+	registerFunc(40, "invert-colors", func(opts *options.GlitchOptions) bool {
+		return opts.InvertColorsEnable
+	}, func(ctx *Context) {
+		if ctx.Rand.Float64() < ctx.Opts.InvertColorsProbability {
+			applyInvertColorsGlitch(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Mono)
+		}
+	})
+	registerFunc(50, "char-scramble", func(opts *options.GlitchOptions) bool {
+		return opts.CharScrambleEnable
+	}, func(ctx *Context) {
+		if ctx.Rand.Float64() < ctx.Opts.CharScrambleProbability {
+			applyCharScrambleGlitch(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand)
+		}
+	})
+	registerFunc(60, "tunnel", func(opts *options.GlitchOptions) bool {
+		return opts.TunnelEnable
+	}, func(ctx *Context) {
+		if ctx.Rand.Float64() < ctx.Opts.TunnelProbability {
+			applyTunnelEffect(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Opts)
+		}
+	})
+	registerFunc(70, "block-distort", func(opts *options.GlitchOptions) bool {
+		return opts.BlockDistortionEnable
+	}, func(ctx *Context) {
+		if ctx.Rand.Intn(10) < 1 {
+			blockDistortionGlitch(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand)
+		}
+	})
+	registerFunc(80, "scanline", func(opts *options.GlitchOptions) bool {
+		return opts.ScanlineEnable
+	}, func(ctx *Context) {
+		applyScanlineEffect(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Opts, ctx.CharSet, ctx.Theme, ctx.Mono)
+	})
+	registerFunc(90, "color-cycle", func(opts *options.GlitchOptions) bool {
+		return opts.ColorCycleEnable
+	}, func(ctx *Context) {
+		ctx.engine.applyColorCycle(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Opts, ctx.Theme.Cycle, ctx.Mono)
+	})
+	registerFunc(100, "smear", func(opts *options.GlitchOptions) bool {
+		return opts.SmearEnable
+	}, func(ctx *Context) {
+		ctx.engine.applySmear(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Opts)
+	})
+	registerFunc(110, "ghosting", func(opts *options.GlitchOptions) bool {
+		return opts.GhostingEnable
+	}, func(ctx *Context) {
+		ctx.engine.applyGhostingEffect(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Opts)
+	})
+	Register(120, func() Effect { return &scrollEffect{} })
+	registerFunc(130, "bitrot", func(opts *options.GlitchOptions) bool {
+		return opts.BitRotEnable
+	}, func(ctx *Context) {
+		charSet, err := ctx.LoadCharSet(ctx.Opts.BitRotCharset)
+		if err != nil {
+			charSet = charset.Legacy(false, true)
+		}
+		applyBitRot(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Opts, charSet)
+	})
+	registerFunc(140, "melt", func(opts *options.GlitchOptions) bool {
+		return opts.MeltEnable
+	}, func(ctx *Context) {
+		applyMelt(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Opts)
+	})
+	registerFunc(150, "jitter", func(opts *options.GlitchOptions) bool {
+		return opts.JitterEnable
+	}, func(ctx *Context) {
+		applyJitter(ctx.Grid, ctx.Width, ctx.Height, ctx.Rand, ctx.Opts)
+	})
 }
 
-func applyBitRot(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
+func applyBitRot(g *frame.Grid, width, height int, rGen *rand.Rand, opts *options.GlitchOptions, charSet *charset.Set) {
 	if !opts.BitRotEnable {
 		return
 	}
@@ -720,16 +739,17 @@ func applyBitRot(s tcell.Screen, width, height int, rGen *rand.Rand, opts *optio
 	for y := 0; y < height; y++ {
 		for x := 0; x < width; x++ {
 			if rGen.Float64() < opts.BitRotProbability {
-				_, style, _ := s.Get(x, y)
-				r := []rune(cp437Chars)[rGen.Intn(len(cp437Chars))] // Use the pre-converted slice
+				c := g.Get(x, y)
+				c.Rune = charSet.Pick(rGen)
+				c.Combining = nil
 
-				s.SetContent(x, y, r, nil, style)
+				g.Set(x, y, c)
 			}
 		}
 	}
 }
 
-func applyMelt(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
+func applyMelt(g *frame.Grid, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
 	if !opts.MeltEnable {
 		return
 	}
@@ -737,13 +757,17 @@ func applyMelt(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options
 	for y := height - 2; y >= 0; y-- {
 		for x := 0; x < width; x++ {
 			if rGen.Float64() < opts.MeltProbability {
-				c, style, _ := s.Get(x, y)
-				if y+1 < height { // Bounds check
-					below, _, _ := s.Get(x, y+1)
+				c := g.Get(x, y)
+				if y+1 < height && !c.IsContinuation() { // Bounds check
+					// A wide character needs both cells below it empty
+					empty := true
+					for i := 0; i < c.Width(); i++ {
+						empty = empty && g.Get(x+i, y+1).Rune == ' '
+					}
 
-					if rune(below[0]) == ' ' {
-						s.SetContent(x, y+1, rune(c[0]), nil, style)
-						s.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+					if empty {
+						g.Set(x, y+1, c)
+						g.Set(x, y, frame.Blank)
 					}
 				}
 			}
@@ -751,7 +775,7 @@ func applyMelt(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options
 	}
 }
 
-func applyJitter(s tcell.Screen, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
+func applyJitter(g *frame.Grid, width, height int, rGen *rand.Rand, opts *options.GlitchOptions) {
 	if !opts.JitterEnable {
 		return
 	}
@@ -778,11 +802,16 @@ func applyJitter(s tcell.Screen, width, height int, rGen *rand.Rand, opts *optio
 
 				// Only proceed if the calculated coordinates are within bounds
 				if x >= 0 && x < width && y >= 0 && y < height && nx >= 0 && nx < width && ny >= 0 && ny < height {
-					// Swap cells
-					c1, style1, _ := s.Get(x, y)
-					c2, style2, _ := s.Get(nx, ny)
-					s.SetContent(x, y, rune(c2[0]), nil, style2)
-					s.SetContent(nx, ny, rune(c1[0]), nil, style1)
+					// Swap whole characters, and only characters of the
+					// same width so that no other cell is overwritten
+					hx, hnx := g.Head(x, y), g.Head(nx, ny)
+					c1 := g.Get(hx, y)
+					c2 := g.Get(hnx, ny)
+					if (hx == hnx && y == ny) || c1.Width() != c2.Width() {
+						continue
+					}
+					g.Set(hx, y, c2)
+					g.Set(hnx, ny, c1)
 				}
 			}
 		}
@@ -0,0 +1,185 @@
+package effects
+
+import (
+	"glitch-saver/internal/charset"
+	"glitch-saver/internal/frame"
+	"glitch-saver/internal/options"
+	"glitch-saver/internal/theme"
+	"math/rand"
+)
+
+// Engine owns the effect pipeline and all effect state for a single saver
+// instance. Engines are independent of each other, so several of them can
+// run in the same process. An Engine is not safe for concurrent use.
+//
+// Rendering is deterministic: two engines created with the same options,
+// including GlitchOptions.Seed, and resized to the same size produce
+// identical frame sequences.
+type Engine struct {
+	opts     *options.GlitchOptions
+	pipeline []Effect
+	grid     *frame.Grid
+	rGen     *rand.Rand
+	seed     int64
+	mode     frame.ColorMode
+
+	// theme is the resolved theme and palette, cached because palettes are
+	// loaded from files. themeKey records the options it was resolved from.
+	theme    *theme.Theme
+	themeKey [2]string
+	// charSets caches parsed charset specs, which may refer to files.
+	// charSetsCustom is the -charset-custom value they were parsed with.
+	charSets       map[string]charsetResult
+	charSetsCustom string
+
+	// cyclingCells holds the state of cells that are cycling colors.
+	cyclingCells map[Point]int
+	// smearBuffer and ghostBuffer hold the trails left by corrupted characters.
+	smearBuffer [][]SmearCell
+	ghostBuffer [][]SmearCell
+}
+
+// NewEngine creates an engine with a fresh instance of every registered
+// effect and a random number generator seeded with opts.Seed. The color mode
+// is taken from opts.ColorMode, with "auto" meaning true color.
+func NewEngine(opts *options.GlitchOptions) *Engine {
+	mode, _ := frame.ParseColorMode(opts.ColorMode)
+	return &Engine{
+		opts:         opts,
+		pipeline:     newPipeline(),
+		grid:         frame.NewGrid(0, 0),
+		rGen:         rand.New(rand.NewSource(opts.Seed)),
+		seed:         opts.Seed,
+		mode:         mode,
+		cyclingCells: make(map[Point]int),
+	}
+}
+
+// Seed returns the seed the engine's random number generator was last
+// seeded with.
+func (e *Engine) Seed() int64 {
+	return e.seed
+}
+
+// Reseed restarts the engine's random number generator from seed.
+func (e *Engine) Reseed(seed int64) {
+	e.seed = seed
+	e.rGen.Seed(seed)
+}
+
+// SetColorMode tells the engine which colors the output can display. In Mono
+// mode the effects use text attributes instead of colors.
+func (e *Engine) SetColorMode(mode frame.ColorMode) {
+	e.mode = mode
+}
+
+// Options returns the options the engine was created with.
+func (e *Engine) Options() *options.GlitchOptions {
+	return e.opts
+}
+
+// Enabled returns the names of the effects that run with the current
+// options, in pipeline order.
+func (e *Engine) Enabled() []string {
+	var names []string
+	for _, effect := range e.pipeline {
+		if effect.Enabled(e.opts) {
+			names = append(names, effect.Name())
+		}
+	}
+	return names
+}
+
+// Grid returns the cell grid the effects draw on.
+func (e *Engine) Grid() *frame.Grid {
+	return e.grid
+}
+
+// Resize clears the grid and resets all effect state for a screen of the
+// given size.
+func (e *Engine) Resize(width, height int) {
+	e.grid.Resize(width, height)
+	e.smearBuffer = make([][]SmearCell, height)
+	e.ghostBuffer = make([][]SmearCell, height)
+	for i := range e.smearBuffer {
+		e.smearBuffer[i] = make([]SmearCell, width)
+		e.ghostBuffer[i] = make([]SmearCell, width)
+	}
+	e.cyclingCells = make(map[Point]int)
+
+	for _, effect := range e.pipeline {
+		effect.Init(width, height)
+	}
+}
+
+// currentTheme returns the theme selected by the options, falling back to
+// the theme's own colors or the default theme if it cannot be loaded. Those
+// errors are reported by GlitchOptions.Validate.
+func (e *Engine) currentTheme() *theme.Theme {
+	key := [2]string{e.opts.Theme, e.opts.Palette}
+	if e.theme == nil || key != e.themeKey {
+		e.theme, _ = theme.Resolve(key[0], key[1])
+		e.themeKey = key
+	}
+	return e.theme
+}
+
+type charsetResult struct {
+	set *charset.Set
+	err error
+}
+
+// loadCharSet parses spec once and caches the result until -charset-custom
+// changes.
+func (e *Engine) loadCharSet(spec string) (*charset.Set, error) {
+	if e.charSets == nil || e.charSetsCustom != e.opts.CharsetCustom {
+		e.charSets = make(map[string]charsetResult)
+		e.charSetsCustom = e.opts.CharsetCustom
+	}
+	r, ok := e.charSets[spec]
+	if !ok {
+		r.set, r.err = charset.Parse(spec, e.opts.CharsetCustom)
+		e.charSets[spec] = r
+	}
+	return r.set, r.err
+}
+
+// DrawGlitch runs every enabled effect of the pipeline on the grid. Call
+// Grid().Flush afterwards to display the result.
+func (e *Engine) DrawGlitch() {
+	opts := e.opts
+	width, height := e.grid.Size()
+	if width == 0 || height == 0 {
+		return
+	}
+
+	// -charset overrides -blocks and -cp437. A broken spec is reported by
+	// GlitchOptions.Validate, so fall back to them quietly.
+	charSet := charset.Legacy(opts.UseBlocks, opts.UseCP437)
+	if opts.Charset != "" {
+		if set, err := e.loadCharSet(opts.Charset); err == nil {
+			charSet = set
+		}
+	}
+
+	ctx := &Context{
+		Grid:    e.grid,
+		Width:   width,
+		Height:  height,
+		Rand:    e.rGen,
+		Opts:    opts,
+		CharSet: charSet,
+		Theme:   e.currentTheme(),
+		Mono:    e.mode == frame.Mono,
+		engine:  e,
+	}
+	for _, effect := range e.pipeline {
+		if !effect.Enabled(opts) {
+			continue
+		}
+		effect.Apply(ctx)
+		if ctx.stopped {
+			return
+		}
+	}
+}
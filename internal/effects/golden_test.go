@@ -0,0 +1,264 @@
+package effects_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"glitch-saver/internal/effects"
+	"glitch-saver/internal/frame"
+	"glitch-saver/internal/options"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+const (
+	goldenWidth  = 24
+	goldenHeight = 8
+	goldenFrames = 6
+	goldenSeed   = 1
+)
+
+// goldenCases enables a single effect each. Every effect other than
+// char-corrupt keeps character corruption on so that there is something on
+// the screen to distort.
+var goldenCases = map[string]func(o *options.GlitchOptions){
+	"static":        func(o *options.GlitchOptions) { o.StaticEnable, o.StaticProbability = true, 0.5 },
+	"char-corrupt":  func(o *options.GlitchOptions) {},
+	"shift-line":    func(o *options.GlitchOptions) { o.ShiftLineEnable = true },
+	"vert-line":     func(o *options.GlitchOptions) { o.VerticalLineEnable, o.VerticalLineProbability = true, 0.5 },
+	"invert-colors": func(o *options.GlitchOptions) { o.InvertColorsEnable, o.InvertColorsProbability = true, 0.5 },
+	"char-scramble": func(o *options.GlitchOptions) { o.CharScrambleEnable, o.CharScrambleProbability = true, 0.5 },
+	"tunnel":        func(o *options.GlitchOptions) { o.TunnelEnable, o.TunnelProbability = true, 0.8 },
+	"block-distort": func(o *options.GlitchOptions) { o.BlockDistortionEnable = true },
+	"scanline":      func(o *options.GlitchOptions) { o.ScanlineEnable, o.ScanlineProbability = true, 0.5 },
+	"color-cycle":   func(o *options.GlitchOptions) { o.ColorCycleEnable = true },
+	"smear":         func(o *options.GlitchOptions) { o.SmearEnable = true },
+	"ghosting":      func(o *options.GlitchOptions) { o.GhostingEnable = true },
+	"scroll":        func(o *options.GlitchOptions) { o.ScrollEnable, o.ScrollProbability = true, 0.5 },
+	"bitrot":        func(o *options.GlitchOptions) { o.BitRotEnable, o.BitRotProbability = true, 0.05 },
+	"melt":          func(o *options.GlitchOptions) { o.MeltEnable = true },
+	"jitter":        func(o *options.GlitchOptions) { o.JitterEnable = true },
+}
+
+// defaultOptions returns the options used when no flags are given.
+func defaultOptions(t *testing.T, args ...string) *options.GlitchOptions {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	opts, err := options.ParseArgs(fs, args)
+	if err != nil {
+		t.Fatalf("ParseArgs(%q): %v", args, err)
+	}
+	return opts
+}
+
+// newScreen creates a simulation screen of the given size.
+func newScreen(t *testing.T, width, height int) tcell.SimulationScreen {
+	t.Helper()
+	s := tcell.NewSimulationScreen("UTF-8")
+	if err := s.Init(); err != nil {
+		t.Fatalf("failed to initialize simulation screen: %v", err)
+	}
+	s.SetSize(width, height)
+	t.Cleanup(s.Fini)
+	return s
+}
+
+// renderFrames draws frames with a fresh engine on a simulation screen and
+// returns a text dump of every frame: the runes followed by a hash of the
+// cell styles.
+func renderFrames(t *testing.T, opts *options.GlitchOptions, width, height, frames int) []byte {
+	t.Helper()
+	s := newScreen(t, width, height)
+	engine := effects.NewEngine(opts)
+	engine.Resize(width, height)
+
+	var buf bytes.Buffer
+	for i := 0; i < frames; i++ {
+		engine.DrawGlitch()
+		dumpFrame(&buf, s, engine, i)
+	}
+	return buf.Bytes()
+}
+
+// dumpFrame flushes the engine's grid to s and appends a text dump of it to
+// buf: the runes followed by a hash of the cell styles.
+func dumpFrame(buf *bytes.Buffer, s tcell.SimulationScreen, engine *effects.Engine, index int) {
+	engine.Grid().Flush(s)
+	s.Show()
+
+	cells, w, h := s.GetContents()
+	styles := fnv.New64a()
+	fmt.Fprintf(buf, "frame %d\n", index)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := cells[y*w+x]
+			if len(c.Runes) == 0 {
+				buf.WriteByte(' ')
+			} else {
+				buf.WriteString(string(c.Runes))
+			}
+			fg, bg, attrs := c.Style.Decompose()
+			fmt.Fprintf(styles, "%d/%d/%d;", fg, bg, attrs)
+		}
+		buf.WriteByte('\n')
+	}
+	fmt.Fprintf(buf, "styles %016x\n", styles.Sum64())
+}
+
+func TestGoldenFrames(t *testing.T) {
+	for _, name := range effects.Names() {
+		enable, ok := goldenCases[name]
+		if !ok {
+			t.Errorf("effect %q has no golden test case", name)
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			opts := defaultOptions(t)
+			opts.Seed = goldenSeed
+			enable(opts)
+			got := renderFrames(t, opts, goldenWidth, goldenHeight, goldenFrames)
+
+			path := filepath.Join("testdata", "golden", name+".txt")
+			if *update {
+				if err := os.WriteFile(path, got, 0644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("frames differ from %s (run with -update if the change is intended)\ngot:\n%s\nwant:\n%s", path, got, want)
+			}
+		})
+	}
+}
+
+func TestDeterministicSeed(t *testing.T) {
+	opts := defaultOptions(t, "-all-effects")
+	opts.Seed = 42
+	first := renderFrames(t, opts, 40, 12, 20)
+	second := renderFrames(t, opts, 40, 12, 20)
+	if !bytes.Equal(first, second) {
+		t.Error("two runs with the same seed produced different frames")
+	}
+}
+
+func TestEnginesAreIndependent(t *testing.T) {
+	opts := defaultOptions(t, "-all-effects")
+	opts.Seed = 42
+	want := renderFrames(t, opts, 40, 12, 20)
+
+	// Two engines drawing in turn must not share random numbers or any
+	// effect state
+	var bufs [2]bytes.Buffer
+	var screens [2]tcell.SimulationScreen
+	var engines [2]*effects.Engine
+	for i := range engines {
+		screens[i] = newScreen(t, 40, 12)
+		engines[i] = effects.NewEngine(opts)
+		engines[i].Resize(40, 12)
+	}
+	for f := 0; f < 20; f++ {
+		for i, engine := range engines {
+			engine.DrawGlitch()
+			dumpFrame(&bufs[i], screens[i], engine, f)
+		}
+	}
+	for i := range bufs {
+		if !bytes.Equal(bufs[i].Bytes(), want) {
+			t.Errorf("engine %d drew different frames when interleaved with another", i)
+		}
+	}
+}
+
+func TestTinyScreens(t *testing.T) {
+	sizes := [][2]int{{1, 1}, {2, 1}, {1, 2}, {2, 2}, {3, 3}, {4, 4}, {5, 2}, {80, 1}, {1, 24}}
+	for _, size := range sizes {
+		t.Run(fmt.Sprintf("%dx%d", size[0], size[1]), func(t *testing.T) {
+			opts := defaultOptions(t, "-all-effects")
+			opts.Seed = goldenSeed
+			renderFrames(t, opts, size[0], size[1], 50)
+		})
+	}
+}
+
+func TestMonoUsesAttributesOnly(t *testing.T) {
+	opts := defaultOptions(t, "-all-effects", "-color-mode", "mono")
+	opts.Seed = goldenSeed
+	s := newScreen(t, 40, 12)
+	engine := effects.NewEngine(opts)
+	engine.Resize(40, 12)
+
+	seen := make(map[tcell.AttrMask]bool)
+	for i := 0; i < 20; i++ {
+		engine.DrawGlitch()
+		engine.Grid().FlushMode(s, frame.Mono)
+		s.Show()
+
+		cells, _, _ := s.GetContents()
+		for _, c := range cells {
+			fg, bg, attrs := c.Style.Decompose()
+			if fg != tcell.ColorDefault || bg != tcell.ColorDefault {
+				t.Fatalf("frame %d has a colored cell: fg %v, bg %v", i, fg, bg)
+			}
+			seen[attrs] = true
+		}
+	}
+	for _, attr := range []tcell.AttrMask{tcell.AttrBold, tcell.AttrReverse, tcell.AttrUnderline} {
+		found := false
+		for attrs := range seen {
+			found = found || attrs&attr != 0
+		}
+		if !found {
+			t.Errorf("no cell used attribute %v in mono mode", attr)
+		}
+	}
+}
+
+// TestWideCharacters checks that no effect splits a wide character: every
+// wide character must be followed by its continuation cell and every
+// continuation cell must follow a wide character.
+func TestWideCharacters(t *testing.T) {
+	for _, name := range effects.Names() {
+		t.Run(name, func(t *testing.T) {
+			opts := defaultOptions(t, "-charset", "katakana,ascii")
+			opts.Seed = goldenSeed
+			goldenCases[name](opts)
+			engine := effects.NewEngine(opts)
+			engine.Resize(goldenWidth, goldenHeight)
+
+			wide := 0
+			for i := 0; i < 50; i++ {
+				engine.DrawGlitch()
+				g := engine.Grid()
+				for y := 0; y < goldenHeight; y++ {
+					for x := 0; x < goldenWidth; x++ {
+						c := g.Get(x, y)
+						if c.Width() == 2 {
+							wide++
+							if !g.Get(x+1, y).IsContinuation() {
+								t.Fatalf("frame %d: wide %q at %d,%d has no continuation", i, c.Rune, x, y)
+							}
+						}
+						if c.IsContinuation() && (x == 0 || g.Get(x-1, y).Width() != 2) {
+							t.Fatalf("frame %d: continuation at %d,%d without a wide character", i, x, y)
+						}
+					}
+				}
+			}
+			if wide == 0 && name != "static" {
+				t.Error("no wide characters were drawn")
+			}
+		})
+	}
+}
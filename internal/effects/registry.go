@@ -0,0 +1,134 @@
+package effects
+
+import (
+	"glitch-saver/internal/charset"
+	"glitch-saver/internal/frame"
+	"glitch-saver/internal/options"
+	"glitch-saver/internal/theme"
+	"math/rand"
+	"sort"
+)
+
+// Effect is a single stage of the glitch pipeline. Effects register a Factory
+// with Register, usually from an init function, and every Engine runs its own
+// instance of each enabled effect once per frame in ascending order.
+type Effect interface {
+	// Name returns the unique name of the effect, e.g. "melt".
+	Name() string
+	// Params returns the extra tunable parameters the effect exposes. They
+	// are registered with the options package and read back with
+	// GlitchOptions.Param.
+	Params() []options.Param
+	// Enabled reports whether the effect should run with the given options.
+	Enabled(opts *options.GlitchOptions) bool
+	// Init prepares the effect for a screen of the given size. It is called
+	// before the first frame and again whenever the screen is resized.
+	Init(width, height int)
+	// Apply draws the effect for a single frame.
+	Apply(ctx *Context)
+}
+
+// Factory creates a fresh instance of an effect. Each Engine calls it once, so
+// any state an effect keeps belongs to that engine alone.
+type Factory func() Effect
+
+// Context carries everything an effect needs to draw a single frame. Effects
+// read and write cells through Grid; the engine's caller flushes it to the
+// terminal afterwards. Effects should take their colors from Theme, or vary
+// text attributes instead when Mono is set.
+type Context struct {
+	Grid    *frame.Grid
+	Width   int
+	Height  int
+	Rand    *rand.Rand
+	Opts    *options.GlitchOptions
+	CharSet *charset.Set
+	Theme   *theme.Theme
+	// Mono reports that the output has no colors at all, so effects should
+	// use bold, dim, underline and reverse video to stand out.
+	Mono bool
+
+	engine  *Engine
+	stopped bool
+}
+
+// LoadCharSet returns the character set described by spec, as accepted by
+// charset.Parse. Sets are cached by the engine, so effects may call it every
+// frame.
+func (c *Context) LoadCharSet(spec string) (*charset.Set, error) {
+	return c.engine.loadCharSet(spec)
+}
+
+// Stop prevents the remaining effects in the pipeline from running this frame.
+func (c *Context) Stop() {
+	c.stopped = true
+}
+
+type registration struct {
+	order   int
+	name    string
+	factory Factory
+}
+
+var registry []registration
+
+// Register adds an effect to the pipeline. Effects run in ascending order and
+// effects with the same order run in registration order. Register panics if
+// the factory is nil or an effect with the same name is already registered.
+func Register(order int, factory Factory) {
+	if factory == nil {
+		panic("effects: Register factory is nil")
+	}
+	e := factory()
+	name := e.Name()
+	for _, r := range registry {
+		if r.name == name {
+			panic("effects: Register called twice for effect " + name)
+		}
+	}
+	for _, p := range e.Params() {
+		options.RegisterParam(p)
+	}
+	registry = append(registry, registration{order: order, name: name, factory: factory})
+	sort.SliceStable(registry, func(i, j int) bool {
+		return registry[i].order < registry[j].order
+	})
+}
+
+// Names returns the names of all registered effects in pipeline order.
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, r := range registry {
+		names[i] = r.name
+	}
+	return names
+}
+
+// newPipeline instantiates every registered effect in pipeline order.
+func newPipeline() []Effect {
+	pipeline := make([]Effect, len(registry))
+	for i, r := range registry {
+		pipeline[i] = r.factory()
+	}
+	return pipeline
+}
+
+// funcEffect adapts a stateless draw function to the Effect interface.
+type funcEffect struct {
+	name    string
+	enabled func(opts *options.GlitchOptions) bool
+	apply   func(ctx *Context)
+}
+
+func (e *funcEffect) Name() string                             { return e.name }
+func (e *funcEffect) Params() []options.Param                  { return nil }
+func (e *funcEffect) Enabled(opts *options.GlitchOptions) bool { return e.enabled(opts) }
+func (e *funcEffect) Init(width, height int)                   {}
+func (e *funcEffect) Apply(ctx *Context)                       { e.apply(ctx) }
+
+// registerFunc registers a stateless effect built from plain functions.
+func registerFunc(order int, name string, enabled func(opts *options.GlitchOptions) bool, apply func(ctx *Context)) {
+	Register(order, func() Effect {
+		return &funcEffect{name: name, enabled: enabled, apply: apply}
+	})
+}
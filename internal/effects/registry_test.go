@@ -0,0 +1,114 @@
+package effects
+
+import (
+	"flag"
+	"slices"
+	"testing"
+
+	"glitch-saver/internal/options"
+)
+
+// paramEffect is a stateless effect that exposes parameters.
+type paramEffect struct {
+	funcEffect
+	params []options.Param
+}
+
+func (e *paramEffect) Params() []options.Param { return e.params }
+
+// isolateRegistry lets a test register effects and parameters of its own,
+// restoring the registry and the option schema when it finishes.
+func isolateRegistry(t *testing.T) {
+	t.Helper()
+	saved, schema := registry, options.Schema
+	registry = nil
+	options.Schema = slices.Clone(options.Schema)
+	t.Cleanup(func() { registry, options.Schema = saved, schema })
+}
+
+// testEffect returns a factory for a do-nothing effect with the given name.
+func testEffect(name string, params ...options.Param) Factory {
+	return func() Effect {
+		return &paramEffect{
+			funcEffect: funcEffect{name: name, enabled: func(*options.GlitchOptions) bool { return true }, apply: func(*Context) {}},
+			params:     params,
+		}
+	}
+}
+
+// mustPanic fails the test unless fn panics.
+func mustPanic(t *testing.T, what string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s did not panic", what)
+		}
+	}()
+	fn()
+}
+
+func TestRegisterPanics(t *testing.T) {
+	isolateRegistry(t)
+	mustPanic(t, "registering a nil factory", func() { Register(1, nil) })
+	Register(1, testEffect("twice"))
+	mustPanic(t, "registering an effect twice", func() { Register(2, testEffect("twice")) })
+}
+
+func TestNamesOrder(t *testing.T) {
+	isolateRegistry(t)
+	Register(20, testEffect("b"))
+	Register(10, testEffect("a"))
+	Register(10, testEffect("c"))
+	registerFunc(20, "d", func(*options.GlitchOptions) bool { return true }, func(*Context) {})
+
+	// Ascending order, then registration order among equals
+	if got, want := Names(), []string{"a", "c", "b", "d"}; !slices.Equal(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	var pipeline []string
+	for _, e := range newPipeline() {
+		pipeline = append(pipeline, e.Name())
+	}
+	if !slices.Equal(pipeline, Names()) {
+		t.Errorf("pipeline runs %v, want %v", pipeline, Names())
+	}
+}
+
+func TestRegisterParams(t *testing.T) {
+	isolateRegistry(t)
+	Register(1, testEffect("glow",
+		options.Param{Name: "glow-radius", Usage: "radius of the glow", Default: 0.5, Min: 0, Max: 1},
+		options.Param{Name: "glow-hue", Usage: "hue of the glow", Default: 30},
+	))
+
+	for _, tc := range []struct {
+		args        []string
+		radius, hue float64
+	}{
+		{nil, 0.5, 30},
+		{[]string{"-glow-radius", "0.25", "-glow-hue", "200"}, 0.25, 200},
+		// Parameters with a range are clamped to it
+		{[]string{"-glow-radius", "3"}, 1, 30},
+		{[]string{"-glow-radius", "-1", "-glow-hue", "-5"}, 0, -5},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		opts, err := options.ParseArgs(fs, tc.args)
+		if err != nil {
+			t.Errorf("ParseArgs(%q): %v", tc.args, err)
+			continue
+		}
+		if fs.Lookup("glow-radius") == nil || fs.Lookup("glow-hue") == nil {
+			t.Fatal("the effect's parameters are not flags")
+		}
+		if got := opts.Param("glow-radius"); got != tc.radius {
+			t.Errorf("ParseArgs(%q): glow-radius = %v, want %v", tc.args, got, tc.radius)
+		}
+		if got := opts.Param("glow-hue"); got != tc.hue {
+			t.Errorf("ParseArgs(%q): glow-hue = %v, want %v", tc.args, got, tc.hue)
+		}
+	}
+
+	mustPanic(t, "registering a parameter twice", func() {
+		Register(2, testEffect("glow2", options.Param{Name: "glow-radius"}))
+	})
+}
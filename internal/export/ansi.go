@@ -0,0 +1,87 @@
+// Package export converts rendered frames into shareable formats such as
+// asciinema recordings, animated GIFs and self-contained HTML players.
+package export
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"glitch-saver/internal/frame"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// sgrAttrs maps cell attributes to their SGR parameters.
+var sgrAttrs = []struct {
+	attr  tcell.AttrMask
+	param string
+}{
+	{tcell.AttrBold, "1"},
+	{tcell.AttrDim, "2"},
+	{tcell.AttrItalic, "3"},
+	{tcell.AttrUnderline, "4"},
+	{tcell.AttrBlink, "5"},
+	{tcell.AttrReverse, "7"},
+	{tcell.AttrStrikeThrough, "9"},
+}
+
+// AppendANSI appends the grid to buf as ANSI escape sequences that redraw it
+// from the top-left corner, using truecolor SGR sequences for the cell colors.
+func AppendANSI(buf []byte, g *frame.Grid) []byte {
+	_, height := g.Size()
+	buf = append(buf, "\x1b[H"...)
+	var last frame.Cell
+	first := true
+	for y := 0; y < height; y++ {
+		if y > 0 {
+			buf = append(buf, "\r\n"...)
+		}
+		for _, c := range g.Visible(y) {
+			if first || c.Fg != last.Fg || c.Bg != last.Bg || c.Attrs != last.Attrs {
+				buf = appendSGR(buf, c)
+				last = c
+				first = false
+			}
+			buf = utf8.AppendRune(buf, c.Rune)
+			for _, cr := range c.Combining {
+				buf = utf8.AppendRune(buf, cr)
+			}
+		}
+	}
+	return append(buf, "\x1b[0m"...)
+}
+
+// appendSGR appends a sequence that resets the style and selects the colors
+// and attributes of c.
+func appendSGR(buf []byte, c frame.Cell) []byte {
+	buf = append(buf, "\x1b[0"...)
+	for _, a := range sgrAttrs {
+		if c.Attrs&a.attr != 0 {
+			buf = append(buf, ';')
+			buf = append(buf, a.param...)
+		}
+	}
+	buf = appendColor(buf, c.Fg, "38")
+	buf = appendColor(buf, c.Bg, "48")
+	return append(buf, 'm')
+}
+
+// appendColor appends the SGR parameters selecting color as a truecolor
+// foreground ("38") or background ("48"). Default colors add nothing.
+func appendColor(buf []byte, color tcell.Color, selector string) []byte {
+	if color == tcell.ColorDefault {
+		return buf
+	}
+	r, g, b := color.RGB()
+	if r < 0 {
+		return buf
+	}
+	buf = append(buf, ';')
+	buf = append(buf, selector...)
+	buf = append(buf, ";2;"...)
+	buf = strconv.AppendInt(buf, int64(r), 10)
+	buf = append(buf, ';')
+	buf = strconv.AppendInt(buf, int64(g), 10)
+	buf = append(buf, ';')
+	return strconv.AppendInt(buf, int64(b), 10)
+}
@@ -0,0 +1,34 @@
+package export
+
+import (
+	"testing"
+
+	"glitch-saver/internal/frame"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestAppendANSI(t *testing.T) {
+	g := frame.NewGrid(4, 2)
+	red := frame.Cell{Rune: 'a', Fg: tcell.NewRGBColor(255, 0, 0), Bg: tcell.NewRGBColor(0, 0, 255)}
+	g.Set(0, 0, red)
+	red.Rune = 'b'
+	g.Set(1, 0, red)
+	g.Set(2, 0, frame.Cell{Rune: 'ア', Attrs: tcell.AttrBold | tcell.AttrDim | tcell.AttrReverse})
+
+	// Runs of the same style share a sequence, every sequence starts with
+	// a reset, and the right half of the wide character is not written
+	want := "\x1b[H" +
+		"\x1b[0;38;2;255;0;0;48;2;0;0;255mab" +
+		"\x1b[0;1;2;7mア\r\n" +
+		"\x1b[0m    " +
+		"\x1b[0m"
+	if got := string(AppendANSI(nil, g)); got != want {
+		t.Errorf("AppendANSI =\n%q\nwant\n%q", got, want)
+	}
+
+	// The grid is appended to what the buffer already holds
+	if got := string(AppendANSI([]byte("x"), g)); got != "x"+want {
+		t.Errorf("AppendANSI did not append to the buffer: %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"glitch-saver/internal/frame"
+)
+
+// asciicastHeader is the first line of an asciicast v2 file.
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Asciicast writes frames as an asciinema v2 recording.
+// See https://docs.asciinema.org/manual/asciicast/v2/ for the format.
+type Asciicast struct {
+	w        *bufio.Writer
+	enc      *json.Encoder
+	interval time.Duration
+	frames   int
+	buf      []byte
+}
+
+// NewAsciicast writes the recording header for a terminal of the given size
+// and returns a writer that emits one frame every 1/fps seconds.
+func NewAsciicast(w io.Writer, width, height, fps int) (*Asciicast, error) {
+	bw := bufio.NewWriter(w)
+	a := &Asciicast{
+		w:        bw,
+		enc:      json.NewEncoder(bw),
+		interval: time.Second / time.Duration(fps),
+	}
+	a.enc.SetEscapeHTML(false)
+	header := asciicastHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: time.Now().Unix(),
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	}
+	if err := a.enc.Encode(header); err != nil {
+		return nil, err
+	}
+	// Clear the screen and hide the cursor before the first frame
+	if err := a.writeEvent(0, []byte("\x1b[2J\x1b[?25l")); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// WriteFrame appends the grid as the next frame of the recording.
+func (a *Asciicast) WriteFrame(g *frame.Grid) error {
+	a.buf = AppendANSI(a.buf[:0], g)
+	err := a.writeEvent(time.Duration(a.frames)*a.interval, a.buf)
+	a.frames++
+	return err
+}
+
+// Close restores the cursor and flushes the recording. It does not close the
+// underlying writer.
+func (a *Asciicast) Close() error {
+	if err := a.writeEvent(time.Duration(a.frames)*a.interval, []byte("\x1b[0m\x1b[?25h")); err != nil {
+		return err
+	}
+	return a.w.Flush()
+}
+
+// writeEvent writes an output event at the given offset from the start.
+func (a *Asciicast) writeEvent(at time.Duration, data []byte) error {
+	return a.enc.Encode([]any{at.Seconds(), "o", string(data)})
+}
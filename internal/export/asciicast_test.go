@@ -0,0 +1,67 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"glitch-saver/internal/frame"
+)
+
+func TestAsciicast(t *testing.T) {
+	var buf bytes.Buffer
+	a, err := NewAsciicast(&buf, 3, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := frame.NewGrid(3, 1)
+	g.Set(0, 0, frame.Cell{Rune: '<'})
+	g.Set(1, 0, frame.Cell{Rune: '"'})
+	g.Set(2, 0, frame.Cell{Rune: '\\'})
+	for i := 0; i < 2; i++ {
+		if err := a.WriteFrame(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want a header and 4 events:\n%s", len(lines), buf.String())
+	}
+	var header asciicastHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+	if header.Version != 2 || header.Width != 3 || header.Height != 1 || header.Env["TERM"] == "" {
+		t.Errorf("header = %+v", header)
+	}
+
+	frameData := string(AppendANSI(nil, g))
+	want := []struct {
+		at   float64
+		data string
+	}{
+		{0, "\x1b[2J\x1b[?25l"},
+		{0, frameData},
+		{0.25, frameData},
+		{0.5, "\x1b[0m\x1b[?25h"},
+	}
+	for i, w := range want {
+		var event []any
+		if err := json.Unmarshal([]byte(lines[i+1]), &event); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if len(event) != 3 || event[0] != w.at || event[1] != "o" || event[2] != w.data {
+			t.Errorf("event %d = %q, want [%v \"o\" %q]", i, event, w.at, w.data)
+		}
+	}
+
+	// Characters are escaped as JSON requires, and only then
+	if !strings.Contains(lines[2], `<\"\\`) {
+		t.Errorf("frame event is not escaped as expected: %s", lines[2])
+	}
+}
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"glitch-saver/internal/frame"
+)
+
+// FrameWriter consumes rendered frames one at a time.
+type FrameWriter interface {
+	// WriteFrame appends the grid as the next frame.
+	WriteFrame(g *frame.Grid) error
+	// Close finishes the output. It does not close the underlying writer.
+	Close() error
+}
+
+// Formats lists the supported output formats.
+var Formats = []string{"cast", "gif", "html"}
+
+// FormatFromPath guesses the output format from a file name's extension.
+func FormatFromPath(path string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+}
+
+// New creates a FrameWriter for the named format, recording a terminal of the
+// given size in cells at fps frames per second.
+func New(format string, w io.Writer, width, height, fps int) (FrameWriter, error) {
+	switch format {
+	case "cast":
+		return NewAsciicast(w, width, height, fps)
+	case "gif":
+		return NewGIF(w, width, height, fps), nil
+	case "html":
+		return NewHTML(w, width, height, fps), nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+}
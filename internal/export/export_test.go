@@ -0,0 +1,49 @@
+package export
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFormatFromPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"glitch.cast":      "cast",
+		"out/Glitch.GIF":   "gif",
+		"player.html":      "html",
+		"archive.tar.gz":   "gz",
+		"no-extension":     "",
+		"dir.d/recording.": "",
+	} {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("FormatFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, format := range Formats {
+		w, err := New(format, io.Discard, 4, 2, 10)
+		if err != nil {
+			t.Errorf("New(%q): %v", format, err)
+			continue
+		}
+		var ok bool
+		switch format {
+		case "cast":
+			_, ok = w.(*Asciicast)
+		case "gif":
+			_, ok = w.(*GIF)
+		case "html":
+			_, ok = w.(*HTML)
+		}
+		if !ok {
+			t.Errorf("New(%q) returned a %T", format, w)
+		}
+	}
+
+	_, err := New("mp4", io.Discard, 4, 2, 10)
+	if err == nil || !strings.Contains(err.Error(), `"mp4"`) || !strings.Contains(err.Error(), "cast, gif, html") {
+		t.Errorf("New(\"mp4\") error = %v, want one naming the format and the supported ones", err)
+	}
+}
@@ -0,0 +1,246 @@
+package export
+
+// The built-in bitmap font is 8x8 pixels per glyph. Each glyph is stored as
+// eight rows with the least significant bit as the leftmost pixel. The ASCII
+// glyphs come from the public domain font8x8 by Daniel Hepper; block, box
+// drawing and braille characters are generated, and other characters used by
+// the CP437 charset are approximated by ASCII look-alikes.
+
+const (
+	glyphWidth  = 8
+	glyphHeight = 8
+)
+
+type glyph [glyphHeight]byte
+
+// asciiGlyphs holds the printable ASCII range U+0020 to U+007E.
+var asciiGlyphs = [...]glyph{
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // U+0020 (space)
+	{0x18, 0x3C, 0x3C, 0x18, 0x18, 0x00, 0x18, 0x00}, // U+0021 (!)
+	{0x36, 0x36, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // U+0022 (")
+	{0x36, 0x36, 0x7F, 0x36, 0x7F, 0x36, 0x36, 0x00}, // U+0023 (#)
+	{0x0C, 0x3E, 0x03, 0x1E, 0x30, 0x1F, 0x0C, 0x00}, // U+0024 ($)
+	{0x00, 0x63, 0x33, 0x18, 0x0C, 0x66, 0x63, 0x00}, // U+0025 (%)
+	{0x1C, 0x36, 0x1C, 0x6E, 0x3B, 0x33, 0x6E, 0x00}, // U+0026 (&)
+	{0x06, 0x06, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00}, // U+0027 (')
+	{0x18, 0x0C, 0x06, 0x06, 0x06, 0x0C, 0x18, 0x00}, // U+0028 (()
+	{0x06, 0x0C, 0x18, 0x18, 0x18, 0x0C, 0x06, 0x00}, // U+0029 ())
+	{0x00, 0x66, 0x3C, 0xFF, 0x3C, 0x66, 0x00, 0x00}, // U+002A (*)
+	{0x00, 0x0C, 0x0C, 0x3F, 0x0C, 0x0C, 0x00, 0x00}, // U+002B (+)
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x0C, 0x0C, 0x06}, // U+002C (,)
+	{0x00, 0x00, 0x00, 0x3F, 0x00, 0x00, 0x00, 0x00}, // U+002D (-)
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x0C, 0x0C, 0x00}, // U+002E (.)
+	{0x60, 0x30, 0x18, 0x0C, 0x06, 0x03, 0x01, 0x00}, // U+002F (/)
+	{0x3E, 0x63, 0x73, 0x7B, 0x6F, 0x67, 0x3E, 0x00}, // U+0030 (0)
+	{0x0C, 0x0E, 0x0C, 0x0C, 0x0C, 0x0C, 0x3F, 0x00}, // U+0031 (1)
+	{0x1E, 0x33, 0x30, 0x1C, 0x06, 0x33, 0x3F, 0x00}, // U+0032 (2)
+	{0x1E, 0x33, 0x30, 0x1C, 0x30, 0x33, 0x1E, 0x00}, // U+0033 (3)
+	{0x38, 0x3C, 0x36, 0x33, 0x7F, 0x30, 0x78, 0x00}, // U+0034 (4)
+	{0x3F, 0x03, 0x1F, 0x30, 0x30, 0x33, 0x1E, 0x00}, // U+0035 (5)
+	{0x1C, 0x06, 0x03, 0x1F, 0x33, 0x33, 0x1E, 0x00}, // U+0036 (6)
+	{0x3F, 0x33, 0x30, 0x18, 0x0C, 0x0C, 0x0C, 0x00}, // U+0037 (7)
+	{0x1E, 0x33, 0x33, 0x1E, 0x33, 0x33, 0x1E, 0x00}, // U+0038 (8)
+	{0x1E, 0x33, 0x33, 0x3E, 0x30, 0x18, 0x0E, 0x00}, // U+0039 (9)
+	{0x00, 0x0C, 0x0C, 0x00, 0x00, 0x0C, 0x0C, 0x00}, // U+003A (:)
+	{0x00, 0x0C, 0x0C, 0x00, 0x00, 0x0C, 0x0C, 0x06}, // U+003B (;)
+	{0x18, 0x0C, 0x06, 0x03, 0x06, 0x0C, 0x18, 0x00}, // U+003C (<)
+	{0x00, 0x00, 0x3F, 0x00, 0x00, 0x3F, 0x00, 0x00}, // U+003D (=)
+	{0x06, 0x0C, 0x18, 0x30, 0x18, 0x0C, 0x06, 0x00}, // U+003E (>)
+	{0x1E, 0x33, 0x30, 0x18, 0x0C, 0x00, 0x0C, 0x00}, // U+003F (?)
+	{0x3E, 0x63, 0x7B, 0x7B, 0x7B, 0x03, 0x1E, 0x00}, // U+0040 (@)
+	{0x0C, 0x1E, 0x33, 0x33, 0x3F, 0x33, 0x33, 0x00}, // U+0041 (A)
+	{0x3F, 0x66, 0x66, 0x3E, 0x66, 0x66, 0x3F, 0x00}, // U+0042 (B)
+	{0x3C, 0x66, 0x03, 0x03, 0x03, 0x66, 0x3C, 0x00}, // U+0043 (C)
+	{0x1F, 0x36, 0x66, 0x66, 0x66, 0x36, 0x1F, 0x00}, // U+0044 (D)
+	{0x7F, 0x46, 0x16, 0x1E, 0x16, 0x46, 0x7F, 0x00}, // U+0045 (E)
+	{0x7F, 0x46, 0x16, 0x1E, 0x16, 0x06, 0x0F, 0x00}, // U+0046 (F)
+	{0x3C, 0x66, 0x03, 0x03, 0x73, 0x66, 0x7C, 0x00}, // U+0047 (G)
+	{0x33, 0x33, 0x33, 0x3F, 0x33, 0x33, 0x33, 0x00}, // U+0048 (H)
+	{0x1E, 0x0C, 0x0C, 0x0C, 0x0C, 0x0C, 0x1E, 0x00}, // U+0049 (I)
+	{0x78, 0x30, 0x30, 0x30, 0x33, 0x33, 0x1E, 0x00}, // U+004A (J)
+	{0x67, 0x66, 0x36, 0x1E, 0x36, 0x66, 0x67, 0x00}, // U+004B (K)
+	{0x0F, 0x06, 0x06, 0x06, 0x46, 0x66, 0x7F, 0x00}, // U+004C (L)
+	{0x63, 0x77, 0x7F, 0x7F, 0x6B, 0x63, 0x63, 0x00}, // U+004D (M)
+	{0x63, 0x67, 0x6F, 0x7B, 0x73, 0x63, 0x63, 0x00}, // U+004E (N)
+	{0x1C, 0x36, 0x63, 0x63, 0x63, 0x36, 0x1C, 0x00}, // U+004F (O)
+	{0x3F, 0x66, 0x66, 0x3E, 0x06, 0x06, 0x0F, 0x00}, // U+0050 (P)
+	{0x1E, 0x33, 0x33, 0x33, 0x3B, 0x1E, 0x38, 0x00}, // U+0051 (Q)
+	{0x3F, 0x66, 0x66, 0x3E, 0x36, 0x66, 0x67, 0x00}, // U+0052 (R)
+	{0x1E, 0x33, 0x07, 0x0E, 0x38, 0x33, 0x1E, 0x00}, // U+0053 (S)
+	{0x3F, 0x2D, 0x0C, 0x0C, 0x0C, 0x0C, 0x1E, 0x00}, // U+0054 (T)
+	{0x33, 0x33, 0x33, 0x33, 0x33, 0x33, 0x3F, 0x00}, // U+0055 (U)
+	{0x33, 0x33, 0x33, 0x33, 0x33, 0x1E, 0x0C, 0x00}, // U+0056 (V)
+	{0x63, 0x63, 0x63, 0x6B, 0x7F, 0x77, 0x63, 0x00}, // U+0057 (W)
+	{0x63, 0x63, 0x36, 0x1C, 0x1C, 0x36, 0x63, 0x00}, // U+0058 (X)
+	{0x33, 0x33, 0x33, 0x1E, 0x0C, 0x0C, 0x1E, 0x00}, // U+0059 (Y)
+	{0x7F, 0x63, 0x31, 0x18, 0x4C, 0x66, 0x7F, 0x00}, // U+005A (Z)
+	{0x1E, 0x06, 0x06, 0x06, 0x06, 0x06, 0x1E, 0x00}, // U+005B ([)
+	{0x03, 0x06, 0x0C, 0x18, 0x30, 0x60, 0x40, 0x00}, // U+005C (\)
+	{0x1E, 0x18, 0x18, 0x18, 0x18, 0x18, 0x1E, 0x00}, // U+005D (])
+	{0x08, 0x1C, 0x36, 0x63, 0x00, 0x00, 0x00, 0x00}, // U+005E (^)
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xFF}, // U+005F (_)
+	{0x0C, 0x0C, 0x18, 0x00, 0x00, 0x00, 0x00, 0x00}, // U+0060 (`)
+	{0x00, 0x00, 0x1E, 0x30, 0x3E, 0x33, 0x6E, 0x00}, // U+0061 (a)
+	{0x07, 0x06, 0x06, 0x3E, 0x66, 0x66, 0x3B, 0x00}, // U+0062 (b)
+	{0x00, 0x00, 0x1E, 0x33, 0x03, 0x33, 0x1E, 0x00}, // U+0063 (c)
+	{0x38, 0x30, 0x30, 0x3E, 0x33, 0x33, 0x6E, 0x00}, // U+0064 (d)
+	{0x00, 0x00, 0x1E, 0x33, 0x3F, 0x03, 0x1E, 0x00}, // U+0065 (e)
+	{0x1C, 0x36, 0x06, 0x0F, 0x06, 0x06, 0x0F, 0x00}, // U+0066 (f)
+	{0x00, 0x00, 0x6E, 0x33, 0x33, 0x3E, 0x30, 0x1F}, // U+0067 (g)
+	{0x07, 0x06, 0x36, 0x6E, 0x66, 0x66, 0x67, 0x00}, // U+0068 (h)
+	{0x0C, 0x00, 0x0E, 0x0C, 0x0C, 0x0C, 0x1E, 0x00}, // U+0069 (i)
+	{0x30, 0x00, 0x30, 0x30, 0x30, 0x33, 0x33, 0x1E}, // U+006A (j)
+	{0x07, 0x06, 0x66, 0x36, 0x1E, 0x36, 0x67, 0x00}, // U+006B (k)
+	{0x0E, 0x0C, 0x0C, 0x0C, 0x0C, 0x0C, 0x1E, 0x00}, // U+006C (l)
+	{0x00, 0x00, 0x33, 0x7F, 0x7F, 0x6B, 0x63, 0x00}, // U+006D (m)
+	{0x00, 0x00, 0x1F, 0x33, 0x33, 0x33, 0x33, 0x00}, // U+006E (n)
+	{0x00, 0x00, 0x1E, 0x33, 0x33, 0x33, 0x1E, 0x00}, // U+006F (o)
+	{0x00, 0x00, 0x3B, 0x66, 0x66, 0x3E, 0x06, 0x0F}, // U+0070 (p)
+	{0x00, 0x00, 0x6E, 0x33, 0x33, 0x3E, 0x30, 0x78}, // U+0071 (q)
+	{0x00, 0x00, 0x3B, 0x6E, 0x66, 0x06, 0x0F, 0x00}, // U+0072 (r)
+	{0x00, 0x00, 0x3E, 0x03, 0x1E, 0x30, 0x1F, 0x00}, // U+0073 (s)
+	{0x08, 0x0C, 0x3E, 0x0C, 0x0C, 0x2C, 0x18, 0x00}, // U+0074 (t)
+	{0x00, 0x00, 0x33, 0x33, 0x33, 0x33, 0x6E, 0x00}, // U+0075 (u)
+	{0x00, 0x00, 0x33, 0x33, 0x33, 0x1E, 0x0C, 0x00}, // U+0076 (v)
+	{0x00, 0x00, 0x63, 0x6B, 0x7F, 0x7F, 0x36, 0x00}, // U+0077 (w)
+	{0x00, 0x00, 0x63, 0x36, 0x1C, 0x36, 0x63, 0x00}, // U+0078 (x)
+	{0x00, 0x00, 0x33, 0x33, 0x33, 0x3E, 0x30, 0x1F}, // U+0079 (y)
+	{0x00, 0x00, 0x3F, 0x19, 0x0C, 0x26, 0x3F, 0x00}, // U+007A (z)
+	{0x38, 0x0C, 0x0C, 0x07, 0x0C, 0x0C, 0x38, 0x00}, // U+007B ({)
+	{0x18, 0x18, 0x18, 0x00, 0x18, 0x18, 0x18, 0x00}, // U+007C (|)
+	{0x07, 0x0C, 0x0C, 0x38, 0x0C, 0x0C, 0x07, 0x00}, // U+007D (})
+	{0x6E, 0x3B, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // U+007E (~)
+}
+
+// blockGlyphs holds the block elements and shades.
+var blockGlyphs = map[rune]glyph{
+	'█': {0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+	'▀': {0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x00},
+	'▄': {0x00, 0x00, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF},
+	'▌': {0x0F, 0x0F, 0x0F, 0x0F, 0x0F, 0x0F, 0x0F, 0x0F},
+	'▐': {0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0},
+	'░': {0x11, 0x44, 0x11, 0x44, 0x11, 0x44, 0x11, 0x44},
+	'▒': {0x55, 0xAA, 0x55, 0xAA, 0x55, 0xAA, 0x55, 0xAA},
+	'▓': {0xEE, 0xBB, 0xEE, 0xBB, 0xEE, 0xBB, 0xEE, 0xBB},
+	'■': {0x00, 0x00, 0x3C, 0x3C, 0x3C, 0x3C, 0x00, 0x00},
+}
+
+// boxLines describes a box drawing character by the weight of its line
+// segments towards the top, bottom, left and right edges: 0 for none, 1 for a
+// light line and 2 for a double line.
+type boxLines struct {
+	up, down, left, right int
+}
+
+var boxGlyphs = map[rune]boxLines{
+	'─': {0, 0, 1, 1}, '│': {1, 1, 0, 0}, '┌': {0, 1, 0, 1}, '┐': {0, 1, 1, 0},
+	'└': {1, 0, 0, 1}, '┘': {1, 0, 1, 0}, '├': {1, 1, 0, 1}, '┤': {1, 1, 1, 0},
+	'┬': {0, 1, 1, 1}, '┴': {1, 0, 1, 1}, '┼': {1, 1, 1, 1},
+	'═': {0, 0, 2, 2}, '║': {2, 2, 0, 0}, '╔': {0, 2, 0, 2}, '╗': {0, 2, 2, 0},
+	'╚': {2, 0, 0, 2}, '╝': {2, 0, 2, 0}, '╠': {2, 2, 0, 2}, '╣': {2, 2, 2, 0},
+	'╦': {0, 2, 2, 2}, '╩': {2, 0, 2, 2}, '╬': {2, 2, 2, 2},
+	'╒': {0, 1, 0, 2}, '╓': {0, 2, 0, 1}, '╕': {0, 1, 2, 0}, '╖': {0, 2, 1, 0},
+	'╘': {1, 0, 0, 2}, '╙': {2, 0, 0, 1}, '╛': {1, 0, 2, 0}, '╜': {2, 0, 1, 0},
+	'╞': {1, 1, 0, 2}, '╟': {2, 2, 0, 1}, '╡': {1, 1, 2, 0}, '╢': {2, 2, 1, 0},
+	'╤': {0, 1, 2, 2}, '╥': {0, 2, 1, 1}, '╧': {1, 0, 2, 2}, '╨': {2, 0, 1, 1},
+	'╪': {1, 1, 2, 2}, '╫': {2, 2, 1, 1},
+}
+
+// lookalikes maps the remaining CP437 characters to similar ASCII glyphs.
+var lookalikes = map[rune]rune{
+	'Ç': 'C', 'ü': 'u', 'é': 'e', 'â': 'a', 'ä': 'a', 'à': 'a', 'å': 'a', 'ç': 'c',
+	'ê': 'e', 'ë': 'e', 'è': 'e', 'ï': 'i', 'î': 'i', 'ì': 'i', 'Ä': 'A', 'Å': 'A',
+	'É': 'E', 'æ': 'a', 'Æ': 'A', 'ô': 'o', 'ö': 'o', 'ò': 'o', 'û': 'u', 'ù': 'u',
+	'ÿ': 'y', 'Ö': 'O', 'Ü': 'U', '¢': 'c', '£': 'L', '¥': 'Y', '₧': 'P', 'ƒ': 'f',
+	'á': 'a', 'í': 'i', 'ó': 'o', 'ú': 'u', 'ñ': 'n', 'Ñ': 'N', 'ª': 'a', 'º': 'o',
+	'¿': '?', '⌐': '-', '¬': '-', '½': '%', '¼': '%', '¡': '!', '«': '<', '»': '>',
+	'α': 'a', 'ß': 'B', 'Γ': 'r', 'π': 'n', 'Σ': 'E', 'σ': 'o', 'µ': 'u', 'τ': 't',
+	'Φ': 'O', 'Θ': 'O', 'Ω': 'O', 'δ': 'd', '∞': '8', 'φ': 'o', 'ε': 'e', '∩': 'n',
+	'≡': '=', '±': '+', '≥': '>', '≤': '<', '⌠': 'f', '⌡': 'J', '÷': '+', '≈': '~',
+	'°': 'o', '∙': '.', '·': '.', '√': 'v', 'ⁿ': 'n', '²': '2',
+}
+
+// missingGlyph is drawn for characters the font cannot represent.
+var missingGlyph = glyph{0x7E, 0x42, 0x42, 0x42, 0x42, 0x42, 0x7E, 0x00}
+
+// glyphFor returns the bitmap used to draw r.
+func glyphFor(r rune) glyph {
+	if r >= 0x20 && r <= 0x7E {
+		return asciiGlyphs[r-0x20]
+	}
+	if r == 0 {
+		return asciiGlyphs[0]
+	}
+	if g, ok := blockGlyphs[r]; ok {
+		return g
+	}
+	if lines, ok := boxGlyphs[r]; ok {
+		return boxGlyph(lines)
+	}
+	if r >= 0x2800 && r <= 0x28FF {
+		return brailleGlyph(byte(r - 0x2800))
+	}
+	if alt, ok := lookalikes[r]; ok {
+		return asciiGlyphs[alt-0x20]
+	}
+	return missingGlyph
+}
+
+// brailleDots maps the bits of a braille pattern, dots 1 to 8, to their
+// column and row in the 2x4 dot grid.
+var brailleDots = [8][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {0, 3}, {1, 3}}
+
+// brailleGlyph draws a braille pattern as 2x1 pixel dots.
+func brailleGlyph(bits byte) glyph {
+	var g glyph
+	for i, dot := range brailleDots {
+		if bits&(1<<i) != 0 {
+			g[dot[1]*2] |= 0x3 << (1 + dot[0]*4)
+		}
+	}
+	return g
+}
+
+// boxGlyph draws the line segments of a box drawing character. Light lines
+// run through the center row and column, double lines one pixel either side.
+func boxGlyph(lines boxLines) glyph {
+	const center = 3
+	var g glyph
+	offsets := func(weight int) []int {
+		if weight == 2 {
+			return []int{center - 1, center + 1}
+		}
+		return []int{center}
+	}
+	if lines.left > 0 || lines.right > 0 {
+		from, to := 0, glyphWidth-1
+		if lines.left == 0 {
+			from = center
+		}
+		if lines.right == 0 {
+			to = center
+		}
+		weight := max(lines.left, lines.right)
+		for _, row := range offsets(weight) {
+			for x := from; x <= to; x++ {
+				g[row] |= 1 << x
+			}
+		}
+	}
+	if lines.up > 0 || lines.down > 0 {
+		from, to := 0, glyphHeight-1
+		if lines.up == 0 {
+			from = center
+		}
+		if lines.down == 0 {
+			to = center
+		}
+		weight := max(lines.up, lines.down)
+		for _, col := range offsets(weight) {
+			for y := from; y <= to; y++ {
+				g[y] |= 1 << col
+			}
+		}
+	}
+	return g
+}
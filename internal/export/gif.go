@@ -0,0 +1,181 @@
+package export
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+
+	"glitch-saver/internal/frame"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	cellPixelWidth = glyphWidth
+	// Glyph rows are doubled so that cells keep a terminal-like 1:2 aspect.
+	cellPixelHeight = glyphHeight * 2
+
+	// minDelay is the shortest frame delay, in hundredths of a second,
+	// that viewers honor. Shorter delays are commonly played back as 10.
+	minDelay = 2
+)
+
+// Colors used for cells drawn with the terminal's default style, matching the
+// default style the TUI sets up.
+var (
+	defaultFg = color.RGBA{255, 255, 255, 255}
+	defaultBg = color.RGBA{0, 0, 0, 255}
+)
+
+// GIF renders frames into an animated GIF using the built-in bitmap font.
+// Frames are kept in memory until Close encodes the animation. Above 50 frames
+// per second, frames are dropped so that no frame is shown for less than
+// minDelay.
+type GIF struct {
+	w             io.Writer
+	width, height int
+	fps           int
+	anim          gif.GIF
+	palette       paletteBuilder
+	// frames is the number of frames written, including dropped ones.
+	frames int
+	// start is the time the last image is shown at, in hundredths of a
+	// second.
+	start int
+}
+
+// NewGIF creates a GIF writer for a terminal of the given size in cells,
+// playing back at fps frames per second.
+func NewGIF(w io.Writer, width, height, fps int) *GIF {
+	return &GIF{w: w, width: width, height: height, fps: fps}
+}
+
+// WriteFrame rasterizes the grid and appends it to the animation.
+func (g *GIF) WriteFrame(grid *frame.Grid) error {
+	// GIF delays are in hundredths of a second. Each image is shown until
+	// the next one is due, which spreads the rounding error across frames
+	// so that the total length stays accurate.
+	n := g.frames
+	g.frames++
+	at, end := n*100/g.fps, (n+1)*100/g.fps
+	if last := len(g.anim.Image) - 1; last >= 0 && at-g.start < minDelay {
+		// Too soon after the last image: show that one for longer
+		g.anim.Delay[last] = end - g.start
+		return nil
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, g.width*cellPixelWidth, g.height*cellPixelHeight), nil)
+	for y := 0; y < g.height; y++ {
+		for x, c := range grid.Visible(y) {
+			if x >= g.width {
+				break
+			}
+			g.drawCell(img, x, y, min(c.Width(), g.width-x), c)
+		}
+	}
+	img.Palette = g.palette.snapshot()
+
+	g.start = at
+	g.anim.Image = append(g.anim.Image, img)
+	g.anim.Delay = append(g.anim.Delay, end-at)
+	return nil
+}
+
+// Close encodes the animation to the underlying writer.
+func (g *GIF) Close() error {
+	if len(g.anim.Image) == 0 {
+		return nil
+	}
+	last := len(g.anim.Delay) - 1
+	g.anim.Delay[last] = max(g.anim.Delay[last], minDelay)
+	return gif.EncodeAll(g.w, &g.anim)
+}
+
+// drawCell draws a cell at the given cell coordinates, stretching its glyph
+// across width cells for wide characters.
+func (g *GIF) drawCell(img *image.Paletted, cx, cy, width int, c frame.Cell) {
+	fg, bg := cellColors(c)
+	fgIndex := g.palette.index(fg)
+	bgIndex := g.palette.index(bg)
+
+	bitmap := glyphFor(c.Rune)
+	if c.Attrs&tcell.AttrBold != 0 {
+		for i, row := range bitmap {
+			bitmap[i] = row | row<<1
+		}
+	}
+	if c.Attrs&tcell.AttrUnderline != 0 {
+		bitmap[glyphHeight-1] = 0xFF
+	}
+
+	x0, y0 := cx*cellPixelWidth, cy*cellPixelHeight
+	for py := 0; py < cellPixelHeight; py++ {
+		row := bitmap[py/2]
+		offset := img.PixOffset(x0, y0+py)
+		for px := 0; px < width*cellPixelWidth; px++ {
+			if row&(1<<(px/width)) != 0 {
+				img.Pix[offset+px] = fgIndex
+			} else {
+				img.Pix[offset+px] = bgIndex
+			}
+		}
+	}
+}
+
+// cellColors returns the colors a cell is displayed with, taking the default
+// colors and the reverse and dim attributes into account.
+func cellColors(c frame.Cell) (fg, bg color.RGBA) {
+	fg = rgba(c.Fg, defaultFg)
+	bg = rgba(c.Bg, defaultBg)
+	if c.Attrs&tcell.AttrReverse != 0 {
+		fg, bg = bg, fg
+	}
+	if c.Attrs&tcell.AttrDim != 0 {
+		fg = color.RGBA{fg.R / 2, fg.G / 2, fg.B / 2, 255}
+	}
+	return fg, bg
+}
+
+// rgba converts a tcell color, falling back to def for the default color.
+func rgba(c tcell.Color, def color.RGBA) color.RGBA {
+	r, g, b := c.RGB()
+	if c == tcell.ColorDefault || r < 0 {
+		return def
+	}
+	return color.RGBA{uint8(r), uint8(g), uint8(b), 255}
+}
+
+// paletteBuilder collects the colors used by the animation. Once the 256
+// colors a GIF can hold are taken, further colors map to the nearest entry.
+type paletteBuilder struct {
+	colors  color.Palette
+	indices map[color.RGBA]uint8
+}
+
+// index returns the palette index for c, adding it if there is room.
+func (p *paletteBuilder) index(c color.RGBA) uint8 {
+	if i, ok := p.indices[c]; ok {
+		return i
+	}
+	if p.indices == nil {
+		p.indices = make(map[color.RGBA]uint8)
+	}
+	var i uint8
+	if len(p.colors) < 256 {
+		i = uint8(len(p.colors))
+		p.colors = append(p.colors, c)
+	} else {
+		i = uint8(p.colors.Index(c))
+	}
+	p.indices[c] = i
+	return i
+}
+
+// snapshot returns a copy of the colors collected so far.
+func (p *paletteBuilder) snapshot() color.Palette {
+	if len(p.colors) == 0 {
+		return color.Palette{defaultBg}
+	}
+	return append(color.Palette(nil), p.colors...)
+}
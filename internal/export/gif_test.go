@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"slices"
+	"testing"
+
+	"glitch-saver/internal/frame"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// encodeGIF writes frames copies of g at fps and decodes the result.
+func encodeGIF(t *testing.T, g *frame.Grid, frames, fps int) *gif.GIF {
+	t.Helper()
+	width, height := g.Size()
+	var buf bytes.Buffer
+	w := NewGIF(&buf, width, height, fps)
+	for i := 0; i < frames; i++ {
+		if err := w.WriteFrame(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return anim
+}
+
+func TestGIF(t *testing.T) {
+	red := tcell.NewRGBColor(255, 0, 0)
+	blue := tcell.NewRGBColor(0, 0, 255)
+	g := frame.NewGrid(3, 2)
+	g.Set(0, 0, frame.Cell{Rune: '█', Fg: red, Bg: blue})
+	g.Set(1, 0, frame.Cell{Rune: ' ', Fg: red, Bg: blue})
+	g.Set(2, 0, frame.Cell{Rune: ' ', Attrs: tcell.AttrReverse})
+	g.Set(0, 1, frame.Cell{Rune: 'ア', Fg: red, Bg: blue})
+
+	anim := encodeGIF(t, g, 3, 30)
+	if len(anim.Image) != 3 {
+		t.Fatalf("decoded %d frames, want 3", len(anim.Image))
+	}
+	if want := []int{3, 3, 4}; !slices.Equal(anim.Delay, want) {
+		t.Errorf("delays = %v, want %v", anim.Delay, want)
+	}
+	img := anim.Image[0]
+	if size := img.Bounds().Size(); size.X != 3*cellPixelWidth || size.Y != 2*cellPixelHeight {
+		t.Errorf("image is %v, want %dx%d", size, 3*cellPixelWidth, 2*cellPixelHeight)
+	}
+
+	rgb := func(r, g, b uint8) color.RGBA { return color.RGBA{r, g, b, 255} }
+	for _, tc := range []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"foreground", 4, 4, rgb(255, 0, 0)},
+		{"background", cellPixelWidth + 4, 4, rgb(0, 0, 255)},
+		{"reverse", 2*cellPixelWidth + 4, 4, defaultFg},
+		// The wide character's glyph is stretched across both of
+		// its cells, in the colors of the character
+		{"wide foreground", 12, cellPixelHeight + 2, rgb(255, 0, 0)},
+		{"wide background", 10, cellPixelHeight + 2, rgb(0, 0, 255)},
+	} {
+		r, g, b, _ := img.At(tc.x, tc.y).RGBA()
+		got := rgb(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+		if got != tc.want {
+			t.Errorf("%s pixel at %d,%d = %v, want %v", tc.name, tc.x, tc.y, got, tc.want)
+		}
+	}
+}
+
+func TestGIFHighFrameRate(t *testing.T) {
+	// At 200 frames per second most frames would be due within the same
+	// hundredth of a second, so frames are dropped instead
+	anim := encodeGIF(t, frame.NewGrid(2, 1), 40, 200)
+	total := 0
+	for i, d := range anim.Delay {
+		if d < minDelay {
+			t.Errorf("frame %d has delay %d, want at least %d", i, d, minDelay)
+		}
+		total += d
+	}
+	if len(anim.Image) > 20 {
+		t.Errorf("kept %d of 40 frames, want at most 20", len(anim.Image))
+	}
+	if total < 20 || total > 20+minDelay {
+		t.Errorf("animation lasts %dcs, want 20cs", total)
+	}
+}
+
+func TestGlyphFor(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		r    rune
+		want glyph
+	}{
+		{"ASCII", 'A', asciiGlyphs['A'-0x20]},
+		{"space", ' ', glyph{}},
+		{"continuation", 0, glyph{}},
+		{"block", '▀', glyph{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0}},
+		{"light box", '─', glyph{0, 0, 0, 0xFF, 0, 0, 0, 0}},
+		{"double box", '║', glyph{0x14, 0x14, 0x14, 0x14, 0x14, 0x14, 0x14, 0x14}},
+		{"braille dot 1", '⠁', glyph{0x06, 0, 0, 0, 0, 0, 0, 0}},
+		{"braille dots 7 and 8", '⣀', glyph{0, 0, 0, 0, 0, 0, 0x66, 0}},
+		{"blank braille", '⠀', glyph{}},
+		{"lookalike", 'é', asciiGlyphs['e'-0x20]},
+		{"missing", 'ア', missingGlyph},
+		{"missing control", '\x07', missingGlyph},
+	} {
+		if got := glyphFor(tc.r); got != tc.want {
+			t.Errorf("%s: glyphFor(%q) = %x, want %x", tc.name, tc.r, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"glitch-saver/internal/frame"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+//go:embed player.html
+var playerHTML string
+
+var playerTemplate = template.Must(template.New("player").Parse(playerHTML))
+
+// htmlRecording is the frame data embedded in the player. Every row is a
+// list of runs alternating between text and an index into Styles.
+type htmlRecording struct {
+	Width  int       `json:"width"`
+	Height int       `json:"height"`
+	FPS    int       `json:"fps"`
+	Styles []string  `json:"styles"`
+	Frames [][][]any `json:"frames"`
+}
+
+// HTML writes frames into a single self-contained HTML page with a small
+// player that renders them in a <pre> grid. Frames are kept in memory until
+// Close writes the page.
+type HTML struct {
+	w      io.Writer
+	rec    htmlRecording
+	styles map[string]int
+}
+
+// NewHTML creates an HTML writer for a terminal of the given size in cells,
+// playing back at fps frames per second.
+func NewHTML(w io.Writer, width, height, fps int) *HTML {
+	return &HTML{
+		w:      w,
+		rec:    htmlRecording{Width: width, Height: height, FPS: fps},
+		styles: make(map[string]int),
+	}
+}
+
+// WriteFrame appends the grid to the recording.
+func (h *HTML) WriteFrame(g *frame.Grid) error {
+	rows := make([][]any, h.rec.Height)
+	var text strings.Builder
+	for y := range rows {
+		var runs []any
+		style := -1
+		for x, c := range g.Visible(y) {
+			if x >= h.rec.Width {
+				break
+			}
+			s := h.styleIndex(c)
+			if s != style && text.Len() > 0 {
+				runs = append(runs, text.String(), style)
+				text.Reset()
+			}
+			style = s
+			text.WriteRune(c.Rune)
+			for _, cr := range c.Combining {
+				text.WriteRune(cr)
+			}
+		}
+		if text.Len() > 0 {
+			runs = append(runs, text.String(), style)
+			text.Reset()
+		}
+		rows[y] = runs
+	}
+	h.rec.Frames = append(h.rec.Frames, rows)
+	return nil
+}
+
+// Close writes the page to the underlying writer.
+func (h *HTML) Close() error {
+	data, err := json.Marshal(h.rec)
+	if err != nil {
+		return err
+	}
+	return playerTemplate.Execute(h.w, struct{ Data string }{string(data)})
+}
+
+// styleIndex returns the index of the CSS declarations for a cell, adding
+// them to the style table when they are new.
+func (h *HTML) styleIndex(c frame.Cell) int {
+	fg, bg := cellColors(c)
+	css := fmt.Sprintf("color:#%02x%02x%02x;background:#%02x%02x%02x", fg.R, fg.G, fg.B, bg.R, bg.G, bg.B)
+	if c.Attrs&tcell.AttrBold != 0 {
+		css += ";font-weight:bold"
+	}
+	if c.Attrs&tcell.AttrItalic != 0 {
+		css += ";font-style:italic"
+	}
+	if c.Attrs&tcell.AttrUnderline != 0 {
+		css += ";text-decoration:underline"
+	}
+	if i, ok := h.styles[css]; ok {
+		return i
+	}
+	i := len(h.rec.Styles)
+	h.rec.Styles = append(h.rec.Styles, css)
+	h.styles[css] = i
+	return i
+}
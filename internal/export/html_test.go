@@ -0,0 +1,89 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"glitch-saver/internal/frame"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestHTML(t *testing.T) {
+	red := tcell.NewRGBColor(255, 0, 0)
+	g := frame.NewGrid(10, 2)
+	for x, r := range "</script>" {
+		g.Set(x, 0, frame.Cell{Rune: r})
+	}
+	g.Set(9, 0, frame.Cell{Rune: 'x', Fg: red})
+	for x, r := range "a&b" {
+		g.Set(x, 1, frame.Cell{Rune: r, Attrs: tcell.AttrBold | tcell.AttrUnderline})
+	}
+	g.Set(3, 1, frame.Cell{Rune: 'ア', Fg: red})
+
+	var b strings.Builder
+	h := NewHTML(&b, 10, 2, 12)
+	if err := h.WriteFrame(g); err != nil {
+		t.Fatal(err)
+	}
+	g.Set(9, 0, frame.Cell{Rune: 'y'})
+	if err := h.WriteFrame(g); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The frames are inlined into a script block, which the runes must
+	// not be able to end or break out of
+	page := b.String()
+	start := strings.Index(page, "const data = ")
+	end := strings.Index(page[start:], ";\n")
+	if start < 0 || end < 0 {
+		t.Fatalf("page does not embed the frame data:\n%s", page)
+	}
+	data := page[start+len("const data = ") : start+end]
+	for _, s := range []string{"<", ">", "&"} {
+		if strings.Contains(data, s) {
+			t.Errorf("frame data contains an unescaped %q: %s", s, data)
+		}
+	}
+	if n := strings.Count(page, "</script>"); n != 1 {
+		t.Errorf("page contains %d closing script tags, want 1", n)
+	}
+
+	var rec htmlRecording
+	if err := json.Unmarshal([]byte(data), &rec); err != nil {
+		t.Fatalf("frame data: %v", err)
+	}
+	if rec.Width != 10 || rec.Height != 2 || rec.FPS != 12 || len(rec.Frames) != 2 {
+		t.Fatalf("recording is %dx%d at %d fps with %d frames, want 10x2 at 12 fps with 2", rec.Width, rec.Height, rec.FPS, len(rec.Frames))
+	}
+
+	// Runs of the same style are merged, and the right half of the wide
+	// character is left out
+	plain := "color:#ffffff;background:#000000"
+	wantStyles := []string{
+		plain,
+		"color:#ff0000;background:#000000",
+		plain + ";font-weight:bold;text-decoration:underline",
+	}
+	if !reflect.DeepEqual(rec.Styles, wantStyles) {
+		t.Errorf("styles = %q, want %q", rec.Styles, wantStyles)
+	}
+	wantFrames := [][][]any{
+		{
+			{"</script>", 0.0, "x", 1.0},
+			{"a&b", 2.0, "ア", 1.0, "     ", 0.0},
+		},
+		{
+			{"</script>y", 0.0},
+			{"a&b", 2.0, "ア", 1.0, "     ", 0.0},
+		},
+	}
+	if !reflect.DeepEqual(rec.Frames, wantFrames) {
+		t.Errorf("frames = %q, want %q", rec.Frames, wantFrames)
+	}
+}
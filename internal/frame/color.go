@@ -0,0 +1,147 @@
+package frame
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ColorMode is the set of colors a terminal can display. Colors outside it
+// are mapped to the nearest available color when a grid is flushed.
+type ColorMode int
+
+const (
+	// TrueColor displays every color as is.
+	TrueColor ColorMode = iota
+	// Colors256 limits colors to the xterm 256-color palette.
+	Colors256
+	// Colors16 limits colors to the 16 ANSI colors.
+	Colors16
+	// Colors8 limits colors to the 8 basic ANSI colors.
+	Colors8
+	// Mono uses the terminal's default colors only.
+	Mono
+)
+
+// ColorModes lists the names accepted by ParseColorMode.
+var ColorModes = []string{"truecolor", "256", "16", "8", "mono"}
+
+var colorModeNames = map[ColorMode]string{
+	TrueColor: "truecolor",
+	Colors256: "256",
+	Colors16:  "16",
+	Colors8:   "8",
+	Mono:      "mono",
+}
+
+func (m ColorMode) String() string {
+	return colorModeNames[m]
+}
+
+// ParseColorMode returns the color mode with the given name.
+func ParseColorMode(name string) (ColorMode, bool) {
+	for m, n := range colorModeNames {
+		if n == name {
+			return m, true
+		}
+	}
+	return TrueColor, false
+}
+
+// DetectColorMode picks the color mode for a terminal that reports the given
+// number of colors, as returned by tcell.Screen.Colors, and the given value
+// of the COLORTERM environment variable.
+func DetectColorMode(colors int, colorterm string) ColorMode {
+	switch ct := strings.ToLower(colorterm); {
+	case ct == "truecolor" || ct == "24bit":
+		return TrueColor
+	case colors >= 1<<24:
+		return TrueColor
+	case colors >= 256:
+		return Colors256
+	case colors >= 16:
+		return Colors16
+	case colors >= 8:
+		return Colors8
+	default:
+		return Mono
+	}
+}
+
+// size returns the number of palette colors available in m, or 0 if it is
+// unrestricted or has no colors at all.
+func (m ColorMode) size() int {
+	switch m {
+	case Colors256:
+		return 256
+	case Colors16:
+		return 16
+	case Colors8:
+		return 8
+	}
+	return 0
+}
+
+// palette returns the colors available in m.
+func (m ColorMode) palette() []tcell.Color {
+	p := make([]tcell.Color, m.size())
+	for i := range p {
+		p[i] = tcell.PaletteColor(i)
+	}
+	return p
+}
+
+// nearest caches the results of tcell.FindColor, which is expensive, per
+// color mode.
+var nearest = struct {
+	sync.Mutex
+	m map[ColorMode]map[tcell.Color]tcell.Color
+}{m: make(map[ColorMode]map[tcell.Color]tcell.Color)}
+
+// Color maps c to the nearest color available in m.
+func (m ColorMode) Color(c tcell.Color) tcell.Color {
+	switch {
+	case m == TrueColor || c == tcell.ColorDefault || c == tcell.ColorReset:
+		return c
+	case m == Mono:
+		return tcell.ColorDefault
+	case c&tcell.ColorIsRGB == 0 && c.Valid() && int(c-tcell.ColorValid) < m.size():
+		// Already a palette color the terminal has
+		return c
+	}
+
+	nearest.Lock()
+	defer nearest.Unlock()
+	cache := nearest.m[m]
+	if cache == nil {
+		cache = make(map[tcell.Color]tcell.Color)
+		nearest.m[m] = cache
+	}
+	if mapped, ok := cache[c]; ok {
+		return mapped
+	}
+	mapped := tcell.FindColor(c, m.palette())
+	cache[c] = mapped
+	return mapped
+}
+
+// Cell returns c with its colors mapped to the nearest colors available in m.
+func (m ColorMode) Cell(c Cell) Cell {
+	c.Fg = m.Color(c.Fg)
+	c.Bg = m.Color(c.Bg)
+	return c
+}
+
+// Convert returns g with the colors of every cell mapped to the nearest colors
+// available in m. It returns g itself in TrueColor mode and a copy otherwise.
+func (m ColorMode) Convert(g *Grid) *Grid {
+	if m == TrueColor {
+		return g
+	}
+	out := &Grid{width: g.width, height: g.height, cells: make([]Cell, len(g.cells))}
+	for i, c := range g.cells {
+		out.cells[i] = m.Cell(c)
+	}
+	return out
+}
@@ -0,0 +1,81 @@
+package frame
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestDetectColorMode(t *testing.T) {
+	for _, tc := range []struct {
+		colors    int
+		colorterm string
+		want      ColorMode
+	}{
+		{256, "truecolor", TrueColor},
+		{8, "24bit", TrueColor},
+		{1 << 24, "", TrueColor},
+		{256, "", Colors256},
+		{88, "", Colors16},
+		{16, "", Colors16},
+		{8, "", Colors8},
+		{0, "", Mono},
+	} {
+		if got := DetectColorMode(tc.colors, tc.colorterm); got != tc.want {
+			t.Errorf("DetectColorMode(%d, %q) = %v, want %v", tc.colors, tc.colorterm, got, tc.want)
+		}
+	}
+}
+
+func TestParseColorMode(t *testing.T) {
+	for _, name := range ColorModes {
+		m, ok := ParseColorMode(name)
+		if !ok || m.String() != name {
+			t.Errorf("ParseColorMode(%q) = %v, %v", name, m, ok)
+		}
+	}
+	for _, name := range colorModeNames {
+		if !slices.Contains(ColorModes, name) {
+			t.Errorf("ColorModes does not list %q", name)
+		}
+	}
+	if _, ok := ParseColorMode("auto"); ok {
+		t.Error("ParseColorMode(auto) succeeded")
+	}
+}
+
+func TestColorModeColor(t *testing.T) {
+	orange := tcell.NewRGBColor(255, 135, 0)
+	for _, tc := range []struct {
+		mode ColorMode
+		in   tcell.Color
+		want tcell.Color
+	}{
+		{TrueColor, orange, orange},
+		{Colors256, orange, tcell.PaletteColor(208)},
+		{Colors16, tcell.NewRGBColor(250, 0, 0), tcell.PaletteColor(9)},
+		{Colors8, tcell.NewRGBColor(250, 0, 0), tcell.PaletteColor(1)},
+		{Colors16, tcell.PaletteColor(3), tcell.PaletteColor(3)},
+		{Colors16, tcell.ColorDefault, tcell.ColorDefault},
+		{Mono, orange, tcell.ColorDefault},
+	} {
+		if got := tc.mode.Color(tc.in); got != tc.want {
+			t.Errorf("%v.Color(%v) = %v, want %v", tc.mode, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestColorModeConvert(t *testing.T) {
+	g := NewGrid(2, 1)
+	g.Set(0, 0, Cell{Rune: 'x', Fg: tcell.NewRGBColor(255, 135, 0)})
+	if Colors256.Convert(g) == g || TrueColor.Convert(g) != g {
+		t.Error("Convert should copy the grid unless the mode is TrueColor")
+	}
+	if got := Colors256.Convert(g).Get(0, 0).Fg; got != tcell.PaletteColor(208) {
+		t.Errorf("converted color = %v, want palette color 208", got)
+	}
+	if got := g.Get(0, 0).Fg; got != tcell.NewRGBColor(255, 135, 0) {
+		t.Errorf("Convert modified the original grid: %v", got)
+	}
+}
@@ -0,0 +1,209 @@
+// Package frame provides the in-memory cell grid that effects draw on. A Grid
+// is independent of any terminal and is copied to a tcell screen with Flush.
+//
+// Wide characters, such as katakana or emoji, take up two columns: the cell
+// holding the character and a continuation cell to its right. The grid keeps
+// the two together; overwriting either half of a wide character blanks the
+// other half, so a character is never left half drawn.
+package frame
+
+import (
+	"iter"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+// Cell is a single character cell: a primary rune with optional combining
+// runes, foreground and background colors and text attributes. A cell whose
+// rune is 0 is the continuation of the wide character to its left.
+type Cell struct {
+	Rune      rune
+	Combining []rune
+	Fg        tcell.Color
+	Bg        tcell.Color
+	Attrs     tcell.AttrMask
+}
+
+// Blank is an empty cell drawn with the default style.
+var Blank = Cell{Rune: ' '}
+
+// NewCell creates a cell holding r drawn with the given style.
+func NewCell(r rune, style tcell.Style) Cell {
+	fg, bg, attrs := style.Decompose()
+	return Cell{Rune: r, Fg: fg, Bg: bg, Attrs: attrs}
+}
+
+// IsContinuation reports whether c is the right half of a wide character.
+func (c Cell) IsContinuation() bool {
+	return c.Rune == 0
+}
+
+// Width returns the number of columns c takes up on a terminal: 2 for wide
+// characters, 0 for continuation cells and 1 otherwise.
+func (c Cell) Width() int {
+	switch {
+	case c.Rune == 0:
+		return 0
+	case c.Rune < 0x7F && c.Combining == nil:
+		return 1
+	}
+	// Measure the cell like tcell does, so that both agree on which
+	// characters are wide, including East Asian ambiguous ones
+	if w := uniseg.StringWidth(string(append([]rune{c.Rune}, c.Combining...))); w > 1 {
+		return 2
+	}
+	return 1
+}
+
+// continuation returns the continuation cell of c.
+func (c Cell) continuation() Cell {
+	return Cell{Fg: c.Fg, Bg: c.Bg, Attrs: c.Attrs}
+}
+
+// Style returns the tcell style equivalent to the cell's colors and attributes.
+func (c Cell) Style() tcell.Style {
+	return tcell.StyleDefault.Foreground(c.Fg).Background(c.Bg).Attributes(c.Attrs)
+}
+
+// Grid is a fixed-size grid of cells addressed by column and row.
+type Grid struct {
+	width, height int
+	cells         []Cell
+}
+
+// NewGrid creates a grid of the given size filled with blank cells.
+func NewGrid(width, height int) *Grid {
+	g := &Grid{}
+	g.Resize(width, height)
+	return g
+}
+
+// Size returns the width and height of the grid.
+func (g *Grid) Size() (width, height int) {
+	return g.width, g.height
+}
+
+// Resize changes the size of the grid and clears it.
+func (g *Grid) Resize(width, height int) {
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+	g.width, g.height = width, height
+	g.cells = make([]Cell, width*height)
+	g.Clear()
+}
+
+// Clear fills the grid with blank cells.
+func (g *Grid) Clear() {
+	for i := range g.cells {
+		g.cells[i] = Blank
+	}
+}
+
+// InBounds reports whether (x, y) lies inside the grid.
+func (g *Grid) InBounds(x, y int) bool {
+	return x >= 0 && x < g.width && y >= 0 && y < g.height
+}
+
+// Get returns the cell at (x, y). Out of range coordinates yield a zero Cell.
+func (g *Grid) Get(x, y int) Cell {
+	if !g.InBounds(x, y) {
+		return Cell{}
+	}
+	return g.cells[y*g.width+x]
+}
+
+// Head returns the column of the character covering (x, y): x-1 for the
+// continuation of a wide character and x otherwise.
+func (g *Grid) Head(x, y int) int {
+	if x > 0 && g.Get(x, y).IsContinuation() {
+		return x - 1
+	}
+	return x
+}
+
+// Visible returns the cells of row y that are drawn, with their columns,
+// leaving out the continuation cells covered by wide characters. Each cell
+// takes up c.Width() columns from its own.
+func (g *Grid) Visible(y int) iter.Seq2[int, Cell] {
+	return func(yield func(int, Cell) bool) {
+		if y < 0 || y >= g.height {
+			return
+		}
+		for x, c := range g.cells[y*g.width : (y+1)*g.width] {
+			if !c.IsContinuation() && !yield(x, c) {
+				return
+			}
+		}
+	}
+}
+
+// Set stores c at (x, y). Out of range coordinates are ignored.
+//
+// A wide character also fills the cell to its right with its continuation,
+// or is replaced by a blank in the last column where it does not fit. Any
+// wide character partly overwritten is blanked. Storing a continuation cell
+// right after its wide character does nothing, so rows of cells read with Get
+// can be copied elsewhere cell by cell; a continuation without its character
+// is stored as a blank.
+func (g *Grid) Set(x, y int, c Cell) {
+	if !g.InBounds(x, y) {
+		return
+	}
+	i := y*g.width + x
+	if c.IsContinuation() {
+		if x > 0 && g.cells[i-1].Width() == 2 {
+			return
+		}
+		c.Rune = ' '
+	}
+	g.unlink(x, y)
+	if c.Width() == 2 {
+		if x+1 >= g.width {
+			c.Rune, c.Combining = ' ', nil
+		} else {
+			g.unlink(x+1, y)
+			g.cells[i+1] = c.continuation()
+		}
+	}
+	g.cells[i] = c
+}
+
+// unlink blanks the other half of the wide character covering (x, y), if
+// any, before the cell is overwritten.
+func (g *Grid) unlink(x, y int) {
+	i := y*g.width + x
+	switch c := g.cells[i]; {
+	case c.IsContinuation():
+		if x > 0 {
+			g.cells[i-1] = Blank
+		}
+	case x+1 < g.width && g.cells[i+1].IsContinuation() && c.Width() == 2:
+		g.cells[i+1] = Blank
+	}
+}
+
+// Flush copies every cell of the grid to the screen. It does not call Show.
+func (g *Grid) Flush(s tcell.Screen) {
+	g.FlushMode(s, TrueColor)
+}
+
+// FlushMode copies every cell of the grid to the screen with its colors
+// mapped to the nearest colors available in mode. It does not call Show.
+func (g *Grid) FlushMode(s tcell.Screen, mode ColorMode) {
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			c := mode.Cell(g.cells[y*g.width+x])
+			if c.IsContinuation() {
+				// Hidden behind the wide character to its left, but
+				// shown again if something is drawn over that one
+				c.Rune = ' '
+			}
+			s.SetContent(x, y, c.Rune, c.Combining, c.Style())
+		}
+	}
+}
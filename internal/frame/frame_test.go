@@ -0,0 +1,119 @@
+package frame
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestCellWidth(t *testing.T) {
+	for _, tc := range []struct {
+		c    Cell
+		want int
+	}{
+		{Cell{Rune: 'a'}, 1},
+		{Cell{Rune: '░'}, 1},
+		{Cell{Rune: 'ア'}, 2},
+		{Cell{Rune: '😀'}, 2},
+		{Cell{Rune: 'e', Combining: []rune{'́'}}, 1},
+		{Cell{}, 0},
+	} {
+		if got := tc.c.Width(); got != tc.want {
+			t.Errorf("Width(%q) = %d, want %d", tc.c.Rune, got, tc.want)
+		}
+	}
+}
+
+// row returns the runes of a grid row, with continuation cells as '>'.
+func row(g *Grid, y int) string {
+	width, _ := g.Size()
+	var s []rune
+	for x := 0; x < width; x++ {
+		c := g.Get(x, y)
+		if c.IsContinuation() {
+			s = append(s, '>')
+		} else {
+			s = append(s, c.Rune)
+		}
+	}
+	return string(s)
+}
+
+func TestGridWideCharacters(t *testing.T) {
+	g := NewGrid(6, 2)
+	g.Set(1, 0, Cell{Rune: 'ア'})
+	g.Set(3, 0, Cell{Rune: 'イ'})
+	if got, want := row(g, 0), " ア>イ> "; got != want {
+		t.Fatalf("row = %q, want %q", got, want)
+	}
+	if g.Head(2, 0) != 1 || g.Head(1, 0) != 1 || g.Head(0, 0) != 0 {
+		t.Errorf("Head does not find the wide character")
+	}
+
+	// Overwriting either half blanks the other one
+	g.Set(2, 0, Cell{Rune: 'x'})
+	g.Set(3, 0, Cell{Rune: 'y'})
+	if got, want := row(g, 0), "  xy  "; got != want {
+		t.Errorf("row after overwriting = %q, want %q", got, want)
+	}
+
+	// A wide character does not fit in the last column, nor does a
+	// continuation stand on its own
+	g.Set(5, 0, Cell{Rune: 'ウ'})
+	g.Set(0, 0, Cell{})
+	if got, want := row(g, 0), "  xy  "; got != want {
+		t.Errorf("row after clipping = %q, want %q", got, want)
+	}
+
+	// Copying cells one by one moves wide characters intact
+	g.Set(0, 1, Cell{Rune: 'エ'})
+	g.Set(2, 1, Cell{Rune: 'z'})
+	cells := []Cell{g.Get(0, 1), g.Get(1, 1), g.Get(2, 1)}
+	for i, c := range cells {
+		g.Set(3+i, 1, c)
+	}
+	if got, want := row(g, 1), "エ>zエ>z"; got != want {
+		t.Errorf("copied row = %q, want %q", got, want)
+	}
+}
+
+func TestFlushWideCharacters(t *testing.T) {
+	s := tcell.NewSimulationScreen("UTF-8")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Fini()
+	s.SetSize(4, 1)
+
+	// Text drawn over the grid, such as a status line, is replaced when
+	// the next frame is flushed, even where it is under a wide character
+	s.SetContent(0, 0, 'x', nil, tcell.StyleDefault)
+	s.SetContent(1, 0, 'y', nil, tcell.StyleDefault)
+	g := NewGrid(4, 1)
+	g.Set(0, 0, Cell{Rune: 'ア'})
+	g.Flush(s)
+	s.SetContent(0, 0, ' ', nil, tcell.StyleDefault)
+	s.Show()
+	if str, _, _ := s.Get(1, 0); str != " " {
+		t.Errorf("screen shows %q next to the wide character, want a blank", str)
+	}
+}
+
+func TestGridVisible(t *testing.T) {
+	g := NewGrid(5, 2)
+	g.Set(0, 0, Cell{Rune: 'a'})
+	g.Set(1, 0, Cell{Rune: 'ア'})
+	g.Set(4, 0, Cell{Rune: 'b'})
+	var got []string
+	for x, c := range g.Visible(0) {
+		got = append(got, fmt.Sprintf("%d:%c/%d", x, c.Rune, c.Width()))
+	}
+	if want := "0:a/1 1:ア/2 3: /1 4:b/1"; strings.Join(got, " ") != want {
+		t.Errorf("Visible(0) = %s, want %s", strings.Join(got, " "), want)
+	}
+	for range g.Visible(2) {
+		t.Error("Visible yields cells of a row outside the grid")
+	}
+}
@@ -0,0 +1,76 @@
+// Package headless renders glitch frames without a terminal, so previews can
+// be generated in CI containers and bug reports reproduced without a TTY.
+package headless
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"glitch-saver/internal/effects"
+	"glitch-saver/internal/frame"
+	"glitch-saver/internal/options"
+)
+
+// Config describes the virtual screen of a headless run.
+type Config struct {
+	Width  int
+	Height int
+	Frames int
+}
+
+// Render draws cfg.Frames frames with a fresh engine and calls fn after each
+// one. Given the same options, including the seed, the frames are identical
+// on every run. The grid passed to fn is reused between frames, so fn must copy
+// anything it wants to keep. Rendering stops at the first error fn returns.
+func Render(opts *options.GlitchOptions, cfg Config, fn func(index int, g *frame.Grid) error) error {
+	if cfg.Width < 1 || cfg.Height < 1 {
+		return fmt.Errorf("invalid screen size %dx%d", cfg.Width, cfg.Height)
+	}
+	engine := effects.NewEngine(opts)
+	engine.Resize(cfg.Width, cfg.Height)
+	for i := 0; i < cfg.Frames; i++ {
+		engine.DrawGlitch()
+		if err := fn(i, engine.Grid()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteText writes the runes of a grid as plain text, one line per row.
+func WriteText(w io.Writer, g *frame.Grid) error {
+	bw := bufio.NewWriter(w)
+	_, height := g.Size()
+	for y := 0; y < height; y++ {
+		for _, c := range g.Visible(y) {
+			bw.WriteRune(c.Rune)
+			for _, cr := range c.Combining {
+				bw.WriteRune(cr)
+			}
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// WriteFrames renders frames into dir as numbered text files
+// (frame-00000.txt, frame-00001.txt, ...), creating dir if needed.
+func WriteFrames(dir string, opts *options.GlitchOptions, cfg Config) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return Render(opts, cfg, func(index int, g *frame.Grid) error {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("frame-%05d.txt", index)))
+		if err != nil {
+			return err
+		}
+		if err := WriteText(f, g); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
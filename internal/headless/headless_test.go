@@ -0,0 +1,74 @@
+package headless_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"glitch-saver/internal/frame"
+	"glitch-saver/internal/headless"
+	"glitch-saver/internal/options"
+)
+
+func TestWriteFrames(t *testing.T) {
+	opts, err := options.ParseArgs(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-seed", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "frames")
+	if err := headless.WriteFrames(dir, opts, headless.Config{Width: 12, Height: 3, Frames: 2}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"frame-00000.txt": "k'2\"QgmH25[c\n]Cl!rir}Yv{!\n|fa&1(C$,Qpr\n",
+		"frame-00001.txt": "Tea-^V;9>_+{\n&_Z_c):A~>]p\nE-I!o|@N:{/Q\n",
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		t.Errorf("wrote %d files, want %d", len(entries), len(want))
+	}
+	for name, text := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != text {
+			t.Errorf("%s =\n%s\nwant\n%s", name, data, text)
+		}
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	g := frame.NewGrid(5, 2)
+	g.Set(0, 0, frame.Cell{Rune: 'a'})
+	g.Set(1, 0, frame.Cell{Rune: 'ア'})
+	g.Set(3, 0, frame.Cell{Rune: 'e', Combining: []rune{'\u0301'}})
+	g.Set(4, 0, frame.Cell{Rune: 'b'})
+	var b strings.Builder
+	if err := headless.WriteText(&b, g); err != nil {
+		t.Fatal(err)
+	}
+	// The wide character covers two columns but is written once
+	if got, want := b.String(), "aアe\u0301b\n     \n"; got != want {
+		t.Errorf("WriteText = %q, want %q", got, want)
+	}
+}
+
+func TestRenderInvalidSize(t *testing.T) {
+	opts, err := options.ParseArgs(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = headless.Render(opts, headless.Config{Width: 0, Height: 3, Frames: 1}, func(int, *frame.Grid) error {
+		t.Error("rendered a frame on an empty screen")
+		return nil
+	})
+	if err == nil {
+		t.Error("Render accepted a 0x3 screen")
+	}
+}
@@ -0,0 +1,48 @@
+package options
+
+import (
+	"encoding/json"
+)
+
+// MarshalJSON encodes the options as an object keyed by option name, the
+// format used by preset files.
+func (o GlitchOptions) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(Schema))
+	for _, opt := range Schema {
+		if !opt.NoPreset {
+			m[opt.Name] = opt.Value(&o)
+		}
+	}
+	return json.Marshal(m)
+}
+
+// MarshalPreset encodes the options of o that differ from their defaults as
+// an indented JSON preset of the current PresetVersion. Leaving the defaults
+// out keeps presets small and lets them pick up better defaults in later
+// versions.
+func MarshalPreset(o *GlitchOptions) ([]byte, error) {
+	defaults := Defaults()
+	m := make(map[string]any)
+	for _, opt := range Schema {
+		if !opt.NoPreset && opt.Format(o) != opt.Format(defaults) {
+			m[opt.Name] = opt.Value(o)
+		}
+	}
+	m["version"] = PresetVersion
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// UnmarshalJSON sets the options present in data, a preset of any version,
+// and leaves the others unchanged. Unknown keys are ignored; use PresetLayer
+// to get warnings about them.
+func (o *GlitchOptions) UnmarshalJSON(data []byte) error {
+	values, _, err := decodePreset(data)
+	if err != nil {
+		return err
+	}
+	return o.apply(Layer{Source: "preset", Values: values})
+}
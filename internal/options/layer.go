@@ -0,0 +1,101 @@
+package options
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Layer is a set of option values from a single configuration source, keyed
+// by option name and in flag syntax. When options are resolved, later layers
+// override earlier ones.
+type Layer struct {
+	// Source names where the values came from in error messages, e.g. the
+	// path of a config file.
+	Source string
+	Values map[string]string
+	// Warnings lists problems found while reading the source that did not
+	// prevent it from being used, such as unknown preset keys.
+	Warnings []string
+}
+
+// Resolve starts from the defaults, applies layers in order, validates the
+// result and finalizes it. The usual order is config file, preset,
+// environment and finally the flags given on the command line. The issues
+// found by Validate are returned with their sources filled in; the returned
+// options have already been corrected.
+func Resolve(layers ...Layer) (*GlitchOptions, []Issue, error) {
+	opts := Defaults()
+	sources := make(map[string]string)
+	for _, l := range layers {
+		if err := opts.apply(l); err != nil {
+			return nil, nil, err
+		}
+		for name := range l.Values {
+			sources[name] = l.Source
+		}
+	}
+	issues := opts.Validate()
+	for i := range issues {
+		issues[i].Source = sources[issues[i].Option]
+	}
+	opts.Finalize()
+	return opts, issues, nil
+}
+
+// apply sets every value of l in o.
+func (o *GlitchOptions) apply(l Layer) error {
+	for name, value := range l.Values {
+		opt := Lookup(name)
+		if opt == nil {
+			return fmt.Errorf("%s: unknown option %q", l.Source, name)
+		}
+		if err := opt.Set(o, value); err != nil {
+			return fmt.Errorf("%s: %v", l.Source, err)
+		}
+	}
+	return nil
+}
+
+// FlagLayer returns the options explicitly set on fs, which must have been
+// parsed by ParseArgs.
+func FlagLayer(fs *flag.FlagSet) Layer {
+	l := Layer{Source: "command line", Values: make(map[string]string)}
+	fs.Visit(func(f *flag.Flag) {
+		if v, ok := f.Value.(*flagValue); ok {
+			l.Values[f.Name] = v.raw
+		}
+	})
+	return l
+}
+
+// EnvName returns the environment variable that sets the named option, e.g.
+// GLITCH_SAVER_MELT_PROB for "melt-prob".
+func EnvName(name string) string {
+	return "GLITCH_SAVER_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// EnvLayer returns the options set in the environment. lookup is usually
+// os.LookupEnv.
+func EnvLayer(lookup func(key string) (string, bool)) Layer {
+	l := Layer{Source: "environment", Values: make(map[string]string)}
+	for _, opt := range Schema {
+		if v, ok := lookup(EnvName(opt.Name)); ok {
+			l.Values[opt.Name] = v
+		}
+	}
+	return l
+}
+
+// PresetLayer decodes a preset file of any version, migrating it to the
+// current one.
+func PresetLayer(source string, data []byte) (Layer, error) {
+	values, warnings, err := decodePreset(data)
+	if err != nil {
+		return Layer{}, fmt.Errorf("%s: %v", source, err)
+	}
+	for i, w := range warnings {
+		warnings[i] = source + ": " + w
+	}
+	return Layer{Source: source, Values: values, Warnings: warnings}, nil
+}
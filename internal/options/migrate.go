@@ -0,0 +1,122 @@
+package options
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// PresetVersion is the version of the preset format written by
+// MarshalPreset. Whenever an option is renamed or the meaning of its values
+// changes, bump it and append a migration so that older presets keep working.
+const PresetVersion = 2
+
+// migrations[i] upgrades a decoded preset from version i+1 to version i+2.
+var migrations = []func(p map[string]any){
+	migrateFieldNames,
+}
+
+// migrateFieldNames upgrades version 1 presets, which have no version key.
+// The oldest of them are JSON dumps of GlitchOptions keyed by Go field name,
+// with effect parameters nested in a Params object and the -save-preset and
+// -load-preset paths included. Later ones already use option names.
+func migrateFieldNames(p map[string]any) {
+	if params, ok := p["Params"].(map[string]any); ok {
+		delete(p, "Params")
+		for name, v := range params {
+			p[name] = v
+		}
+	}
+	for key, v := range p {
+		opt := lookupField(key)
+		if opt == nil {
+			continue
+		}
+		delete(p, key)
+		if _, ok := p[opt.Name]; !ok && !opt.NoPreset {
+			p[opt.Name] = v
+		}
+	}
+}
+
+// decodePreset migrates a JSON preset to the current version and converts it
+// to option values in flag syntax. Problems that do not prevent the preset
+// from being used are returned as warnings.
+func decodePreset(data []byte) (values map[string]string, warnings []string, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var p map[string]any
+	if err := dec.Decode(&p); err != nil {
+		return nil, nil, err
+	}
+	if p == nil {
+		return nil, nil, fmt.Errorf("preset must be a JSON object")
+	}
+
+	version := 1
+	if v, ok := p["version"]; ok {
+		n, ok := v.(json.Number)
+		i, err := strconv.Atoi(n.String())
+		if !ok || err != nil || i < 1 {
+			return nil, nil, fmt.Errorf("invalid version %v", v)
+		}
+		version = i
+		delete(p, "version")
+	}
+	if version > PresetVersion {
+		warnings = append(warnings, fmt.Sprintf("preset version %d is newer than the supported version %d", version, PresetVersion))
+	}
+	for ; version < PresetVersion; version++ {
+		migrations[version-1](p)
+	}
+
+	keys := make([]string, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values = make(map[string]string, len(p))
+	for _, key := range keys {
+		opt := Lookup(key)
+		if opt == nil {
+			warnings = append(warnings, fmt.Sprintf("ignoring unknown option %q", key))
+			continue
+		}
+		if opt.NoPreset {
+			warnings = append(warnings, fmt.Sprintf("ignoring option %q, which presets cannot set", key))
+			continue
+		}
+		value, err := scalarValue(p[key])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid value for %s: %v", key, err)
+		}
+		values[key] = value
+	}
+	return values, warnings, nil
+}
+
+// lookupField returns the option stored in the named GlitchOptions field.
+func lookupField(field string) *Option {
+	for _, opt := range Schema {
+		if opt.Field != "" && opt.Field == field {
+			return opt
+		}
+	}
+	return nil
+}
+
+// scalarValue converts a decoded JSON scalar to flag syntax.
+func scalarValue(v any) (string, error) {
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("expected a bool, number or string")
+	}
+}
@@ -2,15 +2,61 @@ package options
 
 import (
 	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 )
 
+// Param describes an extra numeric option contributed by a pluggable effect.
+// Registered params become command-line flags and are stored in
+// GlitchOptions.Params under their name.
+type Param struct {
+	Name    string
+	Usage   string
+	Default float64
+	Min     float64
+	Max     float64
+}
+
+// RegisterParam makes an effect parameter available to ParseOptions by adding
+// it to Schema. It must be called before ParseOptions, typically from an
+// effect's registration.
+func RegisterParam(p Param) {
+	if Lookup(p.Name) != nil {
+		panic("options: param registered twice: " + p.Name)
+	}
+	opt := &Option{
+		Name:    p.Name,
+		Kind:    Float,
+		Default: strconv.FormatFloat(p.Default, 'g', -1, 64),
+		Help:    p.Usage,
+		Group:   effectParamsGroup,
+	}
+	// Clamp to the declared range when one is given
+	if p.Min < p.Max {
+		opt.Range = &Range{p.Min, p.Max}
+	}
+	Schema = append(Schema, opt)
+}
+
 // GlitchOptions holds all configurable parameters for the glitch effects.
+// Every field except Params is described by an entry in Schema.
 type GlitchOptions struct {
 	FPS                     int
+	Seed                    int64
 	Intensity               int
 	UseCP437                bool
 	UseBlocks               bool
+	Charset                 string
+	CharsetCustom           string
 	UseBG                   bool
+	Theme                   string
+	Palette                 string
+	ColorMode               string
 	ShiftLineEnable         bool
 	BlockDistortionEnable   bool
 	CharCorruptionEnable    bool
@@ -37,6 +83,7 @@ type GlitchOptions struct {
 	MeltProbability         float64
 	BitRotEnable            bool
 	BitRotProbability       float64
+	BitRotCharset           string
 	VerticalLineEnable      bool
 	VerticalLineProbability float64
 	InvertColorsEnable      bool
@@ -49,227 +96,138 @@ type GlitchOptions struct {
 	TunnelProbability       float64
 	TunnelSpeed             int
 	AllEffectsEnable        bool
+	Strict                  bool
+	Preset                  string
 	SavePreset              string
 	LoadPreset              string
-	// Add more options here later
+	Params                  map[string]float64
+	// Add more options to Schema
 }
 
-func ParseOptions() *GlitchOptions {
-	var opts GlitchOptions
+// Param returns the value of a registered effect parameter, falling back to
+// its default when it has not been set.
+func (o *GlitchOptions) Param(name string) float64 {
+	if v, ok := o.Params[name]; ok {
+		return v
+	}
+	if opt := Lookup(name); opt != nil && opt.Field == "" {
+		v, _ := strconv.ParseFloat(opt.Default, 64)
+		return v
+	}
+	return 0
+}
 
-	// Define command-line flags and populate opts
-	flag.StringVar(&opts.SavePreset, "save-preset", "", "save the current options to a file")
-	flag.StringVar(&opts.LoadPreset, "load-preset", "", "load options from a file")
-	flag.IntVar(&opts.FPS, "fps", 30, "frames per second for the animation")
-	flag.IntVar(&opts.Intensity, "intensity", 5, "glitch intensity (1-10)")
-	flag.BoolVar(&opts.UseCP437, "cp437", false, "use Code Page 437 characters for a retro effect")
-	flag.BoolVar(&opts.UseBlocks, "blocks", false, "use only block characters for a heavy glitch effect")
-	flag.BoolVar(&opts.UseBG, "bg", false, "enable random background coloring")
-	flag.BoolVar(&opts.ShiftLineEnable, "shift-line", false, "enable horizontal line shift glitch effect")
-	flag.BoolVar(&opts.BlockDistortionEnable, "block-distort", false, "enable block distortion glitch effect")
-	flag.BoolVar(&opts.CharCorruptionEnable, "char-corrupt", true, "enable character corruption glitch effect")
-	flag.BoolVar(&opts.ScanlineEnable, "scanline", false, "enable scanline glitch effect")
-	flag.Float64Var(&opts.ScanlineProbability, "scanline-prob", 0.1, "probability (0.0-1.0) of a scanline appearing each frame")
-	flag.IntVar(&opts.ScanlineIntensity, "scanline-intensity", 5, "intensity (1-10) of scanlines")
-	flag.StringVar(&opts.ScanlineChar, "scanline-char", "", "character to use for scanlines (default: random from current charSet)")
-	flag.BoolVar(&opts.ColorCycleEnable, "color-cycle", false, "enable color cycling effect")
-	flag.IntVar(&opts.ColorCycleSpeed, "color-cycle-speed", 5, "speed (1-10) of color cycling")
-	flag.BoolVar(&opts.SmearEnable, "smear", false, "enable character smearing/trails effect")
-	flag.Float64Var(&opts.SmearProbability, "smear-prob", 0.1, "probability (0.0-1.0) of a character starting to smear")
-	flag.IntVar(&opts.SmearLength, "smear-length", 5, "length of the smear trail (in frames)")
-	flag.BoolVar(&opts.StaticEnable, "static", false, "enable static burst effect")
-	flag.Float64Var(&opts.StaticProbability, "static-prob", 0.01, "probability (0.0-1.0) of a static burst occurring each frame")
-	flag.IntVar(&opts.StaticDuration, "static-duration", 3, "duration of a static burst (in frames)")
-	flag.StringVar(&opts.StaticChar, "static-char", "", "character to use for static bursts (default: random from '. *')")
-	flag.BoolVar(&opts.ScrollEnable, "scroll", false, "enable scrolling blocks effect")
-	flag.Float64Var(&opts.ScrollProbability, "scroll-prob", 0.05, "probability (0.0-1.0) of a new scrolling block appearing each frame")
-	flag.IntVar(&opts.ScrollSpeed, "scroll-speed", 1, "speed of scrolling blocks")
-	flag.StringVar(&opts.ScrollDirection, "scroll-direction", "random", "direction of scrolling blocks (horizontal, vertical, random)")
-	flag.BoolVar(&opts.JitterEnable, "jitter", false, "enable jitter effect")
-	flag.Float64Var(&opts.JitterProbability, "jitter-prob", 0.1, "probability (0.0-1.0) of a character jittering")
-	flag.BoolVar(&opts.MeltEnable, "melt", false, "enable melt effect")
-	flag.Float64Var(&opts.MeltProbability, "melt-prob", 0.1, "probability (0.0-1.0) of a character melting")
-	flag.BoolVar(&opts.BitRotEnable, "bitrot", false, "enable bit-rot effect")
-	flag.Float64Var(&opts.BitRotProbability, "bitrot-prob", 0.1, "probability (0.0-1.0) of a character bit-rotting")
-	flag.BoolVar(&opts.VerticalLineEnable, "vert-line", false, "enable vertical line glitch effect")
-	flag.Float64Var(&opts.VerticalLineProbability, "vert-line-prob", 0.1, "probability (0.0-1.0) of a vertical line appearing each frame")
-	flag.BoolVar(&opts.InvertColorsEnable, "invert-colors", false, "enable invert colors glitch effect")
-	flag.Float64Var(&opts.InvertColorsProbability, "invert-colors-prob", 0.1, "probability (0.0-1.0) of a color inversion appearing each frame")
-	flag.BoolVar(&opts.CharScrambleEnable, "char-scramble", false, "enable character scramble glitch effect")
-	flag.Float64Var(&opts.CharScrambleProbability, "char-scramble-prob", 0.1, "probability (0.0-1.0) of a character scramble appearing each frame")
-	flag.BoolVar(&opts.GhostingEnable, "ghosting", false, "enable ghosting trail effect")
-	flag.Float64Var(&opts.GhostingProbability, "ghosting-prob", 0.1, "probability (0.0-1.0) of a character starting to ghost")
-	flag.BoolVar(&opts.TunnelEnable, "tunnel", false, "enable tunnel/zoom effect")
-	flag.Float64Var(&opts.TunnelProbability, "tunnel-prob", 0.1, "probability (0.0-1.0) of a tunnel/zoom effect appearing each frame")
-	flag.IntVar(&opts.TunnelSpeed, "tunnel-speed", 1, "speed of the tunnel/zoom effect")
-	flag.BoolVar(&opts.AllEffectsEnable, "all-effects", false, "enable all glitch effects")
-	flag.Parse()
+// Defaults returns the options used when nothing is configured.
+func Defaults() *GlitchOptions {
+	opts := &GlitchOptions{Params: make(map[string]float64)}
+	for _, opt := range Schema {
+		if err := opt.Set(opts, opt.Default); err != nil {
+			panic("options: bad default: " + err.Error())
+		}
+	}
+	return opts
+}
 
-	if opts.AllEffectsEnable {
-		opts.UseCP437 = true
-		opts.UseBlocks = true
-		opts.UseBG = true
-		opts.ShiftLineEnable = true
-		opts.BlockDistortionEnable = true
-		opts.CharCorruptionEnable = true
-		opts.ScanlineEnable = true
-		opts.ScanlineProbability = 1.0
-		opts.ScanlineIntensity = 10
-		opts.ColorCycleEnable = true
-		opts.ColorCycleSpeed = 10
-		opts.SmearEnable = true
-		opts.SmearProbability = 1.0
-		opts.SmearLength = 10
-		opts.StaticEnable = true
-		opts.StaticProbability = 0.5 // High probability, but not 1.0 to allow other effects
-		opts.StaticDuration = 5
-		opts.ScrollEnable = true
-		opts.ScrollProbability = 0.5 // High probability, but not 1.0 to allow other effects
-		opts.ScrollSpeed = 5
-		opts.JitterEnable = true
-		opts.JitterProbability = 1.0
-		opts.MeltEnable = true
-		opts.MeltProbability = 1.0
-		opts.BitRotEnable = true
-		opts.BitRotProbability = 1.0
-		opts.VerticalLineEnable = true
-		opts.VerticalLineProbability = 1.0
-		opts.InvertColorsEnable = true
-		opts.InvertColorsProbability = 1.0
-		opts.CharScrambleEnable = true
-		opts.CharScrambleProbability = 1.0
-		opts.GhostingEnable = true
-		opts.GhostingProbability = 1.0
-		opts.TunnelEnable = true
-		opts.TunnelProbability = 0.5 // High probability, but not 1.0
-		opts.TunnelSpeed = 5
-		opts.Intensity = 10
-	}
+// ParseOptions parses the options from the program's command line.
+func ParseOptions() *GlitchOptions {
+	opts, _ := ParseArgs(flag.CommandLine, os.Args[1:])
+	return opts
+}
 
-	// Clamp intensity
-	if opts.Intensity < 1 {
-		opts.Intensity = 1
-	}
-	if opts.Intensity > 10 {
-		opts.Intensity = 10
-	}
-	// Clamp scanline probability
-	if opts.ScanlineProbability < 0.0 {
-		opts.ScanlineProbability = 0.0
-	}
-	if opts.ScanlineProbability > 1.0 {
-		opts.ScanlineProbability = 1.0
-	}
-	// Clamp scanline intensity
-	if opts.ScanlineIntensity < 1 {
-		opts.ScanlineIntensity = 1
-	}
-	if opts.ScanlineIntensity > 10 {
-		opts.ScanlineIntensity = 10
-	}
-	// Clamp color cycle speed
-	if opts.ColorCycleSpeed < 1 {
-		opts.ColorCycleSpeed = 1
-	}
-	if opts.ColorCycleSpeed > 10 {
-		opts.ColorCycleSpeed = 10
-	}
-	// Clamp smear probability
-	if opts.SmearProbability < 0.0 {
-		opts.SmearProbability = 0.0
-	}
-	if opts.SmearProbability > 1.0 {
-		opts.SmearProbability = 1.0
-	}
-	// Clamp smear length
-	if opts.SmearLength < 1 {
-		opts.SmearLength = 1
-	}
-	// Clamp static probability
-	if opts.StaticProbability < 0.0 {
-		opts.StaticProbability = 0.0
-	}
-	if opts.StaticProbability > 1.0 {
-		opts.StaticProbability = 1.0
-	}
-	// Clamp static duration
-	if opts.StaticDuration < 1 {
-		opts.StaticDuration = 1
+// ParseArgs registers the option flags on fs, parses args with it and returns
+// the resulting options. Callers may define additional flags on fs first.
+func ParseArgs(fs *flag.FlagSet, args []string) (*GlitchOptions, error) {
+	opts := Defaults()
+	for _, opt := range Schema {
+		fs.Var(&flagValue{opt: opt, opts: opts}, opt.Name, opt.Help)
 	}
-	// Clamp scroll probability
-	if opts.ScrollProbability < 0.0 {
-		opts.ScrollProbability = 0.0
+	fs.Usage = func() { PrintHelp(fs.Output(), fs) }
+	if err := fs.Parse(args); err != nil {
+		return nil, err
 	}
-	if opts.ScrollProbability > 1.0 {
-		opts.ScrollProbability = 1.0
-	}
-	// Clamp scroll speed
-	if opts.ScrollSpeed < 1 {
-		opts.ScrollSpeed = 1
-	}
-	// Clamp jitter probability
-	if opts.JitterProbability < 0.0 {
-		opts.JitterProbability = 0.0
-	}
-	if opts.JitterProbability > 1.0 {
-		opts.JitterProbability = 1.0
-	}
-	// Clamp melt probability
-	if opts.MeltProbability < 0.0 {
-		opts.MeltProbability = 0.0
-	}
-	if opts.MeltProbability > 1.0 {
-		opts.MeltProbability = 1.0
-	}
-	// Clamp bit-rot probability
-	if opts.BitRotProbability < 0.0 {
-		opts.BitRotProbability = 0.0
-	}
-	if opts.BitRotProbability > 1.0 {
-		opts.BitRotProbability = 1.0
-	}
-	// Clamp vertical line probability
-	if opts.VerticalLineProbability < 0.0 {
-		opts.VerticalLineProbability = 0.0
-	}
-	if opts.VerticalLineProbability > 1.0 {
-		opts.VerticalLineProbability = 1.0
-	}
-	// Clamp invert colors probability
-	if opts.InvertColorsProbability < 0.0 {
-		opts.InvertColorsProbability = 0.0
-	}
-	if opts.InvertColorsProbability > 1.0 {
-		opts.InvertColorsProbability = 1.0
-	}
-	// Clamp char scramble probability
-	if opts.CharScrambleProbability < 0.0 {
-		opts.CharScrambleProbability = 0.0
-	}
-	if opts.CharScrambleProbability > 1.0 {
-		opts.CharScrambleProbability = 1.0
-	}
-	// Clamp ghosting probability
-	if opts.GhostingProbability < 0.0 {
-		opts.GhostingProbability = 0.0
-	}
-	if opts.GhostingProbability > 1.0 {
-		opts.GhostingProbability = 1.0
-	}
-	// Clamp tunnel probability
-	if opts.TunnelProbability < 0.0 {
-		opts.TunnelProbability = 0.0
-	}
-	if opts.TunnelProbability > 1.0 {
-		opts.TunnelProbability = 1.0
+
+	opts.Finalize()
+	return opts, nil
+}
+
+// Finalize applies -all-effects, clamps every option to its range and resets
+// string options that are not among their choices to the default.
+func (o *GlitchOptions) Finalize() {
+	if o.AllEffectsEnable {
+		for _, opt := range Schema {
+			if opt.AllEffects != "" {
+				if err := opt.Set(o, opt.AllEffects); err != nil {
+					panic("options: bad -all-effects value: " + err.Error())
+				}
+			}
+		}
+	}
+	for _, opt := range Schema {
+		opt.clamp(o)
+		if opt.Choices != nil && !slices.Contains(opt.Choices, opt.Format(o)) {
+			opt.set(o, opt.Default)
+		}
 	}
-	// Clamp tunnel speed
-	if opts.TunnelSpeed < 1 {
-		opts.TunnelSpeed = 1
+}
+
+// PrintHelp writes the usage of fs to w. Flags defined by the command itself
+// come first, followed by the options of Schema grouped as declared.
+func PrintHelp(w io.Writer, fs *flag.FlagSet) {
+	name := fs.Name()
+	if name == "" || fs == flag.CommandLine {
+		name = "glitch-saver"
+	}
+	fmt.Fprintf(w, "Usage of %s:\n", name)
+
+	var command []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		if Lookup(f.Name) == nil {
+			command = append(command, f)
+		}
+	})
+	if len(command) > 0 {
+		fmt.Fprintf(w, "\nCommand:\n")
+		for _, f := range command {
+			typ, usage := flag.UnquoteUsage(f)
+			printFlag(w, f.Name, typ, usage, f.DefValue, nil, nil)
+		}
 	}
-	if opts.TunnelSpeed > 10 {
-		opts.TunnelSpeed = 10
+
+	group := ""
+	for _, opt := range Schema {
+		if opt.Group != group {
+			group = opt.Group
+			fmt.Fprintf(w, "\n%s:\n", group)
+		}
+		typ := opt.Kind.String()
+		if opt.Kind == Bool {
+			typ = ""
+		}
+		printFlag(w, opt.Name, typ, opt.Help, opt.Default, opt.Range, opt.Choices)
 	}
+}
 
-	return &opts
+// printFlag writes a single flag in the style of flag.PrintDefaults.
+func printFlag(w io.Writer, name, typ, usage, def string, r *Range, choices []string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  -%s", name)
+	if typ != "" {
+		fmt.Fprintf(&b, " %s", typ)
+	}
+	b.WriteString("\n    \t")
+	b.WriteString(strings.ReplaceAll(usage, "\n", "\n    \t"))
+	if choices != nil {
+		fmt.Fprintf(&b, ": %s", strings.Join(choices, ", "))
+	}
+	if def != "" && def != "false" && def != "0" {
+		fmt.Fprintf(&b, " (default %s)", def)
+	}
+	if r != nil {
+		if math.IsInf(r.Max, 1) {
+			fmt.Fprintf(&b, " [min %g]", r.Min)
+		} else {
+			fmt.Fprintf(&b, " [%g-%g]", r.Min, r.Max)
+		}
+	}
+	fmt.Fprintln(w, b.String())
 }
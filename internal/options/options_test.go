@@ -0,0 +1,280 @@
+package options
+
+import (
+	"encoding/json"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, args ...string) *GlitchOptions {
+	t.Helper()
+	opts, err := ParseArgs(flag.NewFlagSet("test", flag.ContinueOnError), args)
+	if err != nil {
+		t.Fatalf("ParseArgs(%q): %v", args, err)
+	}
+	return opts
+}
+
+func TestSchemaCoversAllFields(t *testing.T) {
+	names := make(map[string]bool)
+	fields := make(map[string]bool)
+	for _, opt := range Schema {
+		if names[opt.Name] {
+			t.Errorf("option %q is declared twice", opt.Name)
+		}
+		names[opt.Name] = true
+		if opt.Field != "" {
+			fields[opt.Field] = true
+		}
+	}
+	typ := reflect.TypeOf(GlitchOptions{})
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Name != "Params" && !fields[f.Name] {
+			t.Errorf("field %s has no schema option", f.Name)
+		}
+	}
+}
+
+func TestParseArgsDefaults(t *testing.T) {
+	if got, want := parse(t), Defaults(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseArgs() = %+v, want defaults %+v", got, want)
+	}
+}
+
+func TestParseArgsClamps(t *testing.T) {
+	opts := parse(t, "-intensity", "50", "-melt-prob", "-1", "-smear-length", "0", "-fps", "0",
+		"-static-duration", "1000", "-scroll-speed", "4611686018427387904")
+	if opts.Intensity != 10 {
+		t.Errorf("Intensity = %d, want 10", opts.Intensity)
+	}
+	if opts.MeltProbability != 0 {
+		t.Errorf("MeltProbability = %v, want 0", opts.MeltProbability)
+	}
+	if opts.SmearLength != 1 {
+		t.Errorf("SmearLength = %d, want 1", opts.SmearLength)
+	}
+	if opts.FPS != 1 {
+		t.Errorf("FPS = %d, want 1", opts.FPS)
+	}
+	if opts.StaticDuration != 100 {
+		t.Errorf("StaticDuration = %d, want 100", opts.StaticDuration)
+	}
+	if opts.ScrollSpeed != 100 {
+		t.Errorf("ScrollSpeed = %d, want 100", opts.ScrollSpeed)
+	}
+	if opts := parse(t, "-fps", "2000000000", "-smear-length", "500"); opts.FPS != 240 || opts.SmearLength != 100 {
+		t.Errorf("FPS = %d, SmearLength = %d, want 240 and 100", opts.FPS, opts.SmearLength)
+	}
+}
+
+func TestParseArgsAllEffects(t *testing.T) {
+	opts := parse(t, "-all-effects")
+	if !opts.MeltEnable || opts.MeltProbability != 1 || opts.Intensity != 10 || opts.StaticProbability != 0.5 {
+		t.Errorf("-all-effects did not apply the schema values: %+v", opts)
+	}
+}
+
+func TestStep(t *testing.T) {
+	opts := Defaults()
+	for _, tc := range []struct {
+		name string
+		n    int
+		want string
+	}{
+		{"melt", 1, "true"},
+		{"melt", 1, "false"},
+		{"intensity", 2, "7"},
+		{"intensity", 10, "10"},
+		{"melt-prob", 3, "0.25"},
+		{"melt-prob", -10, "0"},
+		{"scroll-direction", 1, "horizontal"},
+		{"scroll-direction", -2, "vertical"},
+	} {
+		opt := Lookup(tc.name)
+		if !opt.Step(opts, tc.n) {
+			t.Fatalf("Step(%s) reported the option cannot be stepped", tc.name)
+		}
+		if got := opt.Format(opts); got != tc.want {
+			t.Errorf("Step(%s, %d) = %s, want %s", tc.name, tc.n, got, tc.want)
+		}
+	}
+	if Lookup("scanline-char").Step(opts, 1) {
+		t.Error("Step stepped a free-form string option")
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	want := parse(t, "-melt", "-melt-prob", "0.3", "-scroll-direction", "vertical", "-seed", "42")
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Defaults()
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip through %s = %+v, want %+v", data, got, want)
+	}
+}
+
+func TestUnmarshalLegacyFieldNames(t *testing.T) {
+	got := Defaults()
+	if err := json.Unmarshal([]byte(`{"MeltEnable": true, "FPS": 12, "ScrollDirection": "vertical"}`), got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.MeltEnable || got.FPS != 12 || got.ScrollDirection != "vertical" {
+		t.Errorf("legacy preset not applied: %+v", got)
+	}
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	env := EnvLayer(func(key string) (string, bool) {
+		if key == "GLITCH_SAVER_MELT_PROB" {
+			return "0.5", true
+		}
+		return "", false
+	})
+	opts, _, err := Resolve(
+		Layer{Source: "config", Values: map[string]string{"fps": "10", "melt": "true", "melt-prob": "0.1"}},
+		Layer{Source: "preset", Values: map[string]string{"fps": "20"}},
+		env,
+		Layer{Source: "flags", Values: map[string]string{"fps": "40"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.FPS != 40 || !opts.MeltEnable || opts.MeltProbability != 0.5 {
+		t.Errorf("Resolve = fps %d, melt %v, melt-prob %v; want 40, true, 0.5", opts.FPS, opts.MeltEnable, opts.MeltProbability)
+	}
+}
+
+func TestResolveUnknownOption(t *testing.T) {
+	if _, _, err := Resolve(Layer{Source: "config", Values: map[string]string{"bogus": "1"}}); err == nil {
+		t.Error("Resolve with an unknown option succeeded, want an error")
+	}
+}
+
+func TestFlagLayer(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if _, err := ParseArgs(fs, []string{"-fps", "0", "-melt"}); err != nil {
+		t.Fatal(err)
+	}
+	// Values are passed on as given so that Validate sees them unclamped
+	want := map[string]string{"fps": "0", "melt": "true"}
+	if got := FlagLayer(fs).Values; !reflect.DeepEqual(got, want) {
+		t.Errorf("FlagLayer = %v, want %v", got, want)
+	}
+}
+
+func TestPresetMigration(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+	}{
+		{"go field names", `{"FPS": 12, "MeltEnable": true, "SavePreset": "out.json", "LoadPreset": "", "Params": {}}`},
+		{"option names without version", `{"fps": 12, "melt": true}`},
+		{"current version", `{"version": 2, "fps": 12, "melt": true}`},
+	} {
+		l, err := PresetLayer("test.json", []byte(tc.data))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		want := map[string]string{"fps": "12", "melt": "true"}
+		if !reflect.DeepEqual(l.Values, want) || len(l.Warnings) != 0 {
+			t.Errorf("%s: values %v, warnings %q; want %v and no warnings", tc.name, l.Values, l.Warnings, want)
+		}
+	}
+}
+
+func TestPresetWarnings(t *testing.T) {
+	l, err := PresetLayer("test.json", []byte(`{"version": 9, "fps": 12, "sparkle": true, "save-preset": "x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"test.json: preset version 9 is newer than the supported version 2",
+		`test.json: ignoring option "save-preset", which presets cannot set`,
+		`test.json: ignoring unknown option "sparkle"`,
+	}
+	if !reflect.DeepEqual(l.Warnings, want) {
+		t.Errorf("warnings = %q, want %q", l.Warnings, want)
+	}
+	if l.Values["fps"] != "12" {
+		t.Errorf("fps = %q, want 12", l.Values["fps"])
+	}
+
+	for _, data := range []string{`[]`, `{"version": "two"}`, `{"version": 0}`, `{"fps": [1]}`} {
+		if _, err := PresetLayer("test.json", []byte(data)); err == nil {
+			t.Errorf("PresetLayer(%s) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	opts, issues, err := Resolve(Layer{Source: "command line", Values: map[string]string{
+		"fps":              "0",
+		"melt-prob":        "1.5",
+		"scroll-direction": "sideways",
+		"blocks":           "true",
+		"cp437":            "true",
+		"jitter":           "true",
+		"jitter-prob":      "0",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		"error: -fps: 0 is below the minimum of 1; using 1 (set in command line)",
+		"error: -scroll-direction: \"sideways\" is not one of horizontal, vertical, random; using \"random\" (set in command line)",
+		"warning: -jitter: enabled but -jitter-prob is 0, so it never appears (set in command line)",
+		"error: -melt-prob: 1.5 is above the maximum of 1; using 1 (set in command line)",
+		"warning: -blocks: -blocks and -cp437 are both set; characters are drawn from both sets (set in command line)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if opts.FPS != 1 || opts.MeltProbability != 1 || opts.ScrollDirection != "random" {
+		t.Errorf("options not corrected: fps %d, melt-prob %v, scroll-direction %q", opts.FPS, opts.MeltProbability, opts.ScrollDirection)
+	}
+
+	// Values large enough to overflow the frame interval or the effects
+	// are reported and capped too
+	opts, issues, err = Resolve(Layer{Source: "command line", Values: map[string]string{
+		"fps":             "2000000000",
+		"smear-length":    "1000",
+		"static-duration": "1000",
+		"scroll-speed":    "4611686018427387904",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	want = []string{
+		"error: -fps: 2000000000 is above the maximum of 240; using 240 (set in command line)",
+		"error: -smear-length: 1000 is above the maximum of 100; using 100 (set in command line)",
+		"error: -static-duration: 1000 is above the maximum of 100; using 100 (set in command line)",
+		"error: -scroll-speed: 4611686018427387904 is above the maximum of 100; using 100 (set in command line)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if opts.FPS != 240 || opts.SmearLength != 100 || opts.StaticDuration != 100 || opts.ScrollSpeed != 100 {
+		t.Errorf("options not capped: %+v", opts)
+	}
+
+	if _, issues, _ := Resolve(); len(issues) != 0 {
+		t.Errorf("defaults have issues: %v", issues)
+	}
+}
@@ -0,0 +1,407 @@
+package options
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"glitch-saver/internal/charset"
+	"glitch-saver/internal/frame"
+	"glitch-saver/internal/theme"
+)
+
+// Kind is the value type of an option.
+type Kind int
+
+const (
+	Bool Kind = iota
+	Int
+	Float
+	String
+)
+
+// String returns the name shown for the kind in help output.
+func (k Kind) String() string {
+	switch k {
+	case Bool:
+		return "bool"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	default:
+		return "string"
+	}
+}
+
+// Range is the inclusive range numeric options are clamped to.
+type Range struct {
+	Min, Max float64
+}
+
+var (
+	probability = &Range{0, 1}
+	level       = &Range{1, 10}
+	frameCount  = &Range{1, 100}
+)
+
+// Option describes a single configurable option. The command-line flag, range
+// clamping, preset field, help output and the -all-effects values are all
+// generated from it, so adding an option only means adding it to Schema.
+type Option struct {
+	// Name is the flag name and preset key, e.g. "melt-prob".
+	Name string
+	// Field is the GlitchOptions field holding the value. It is empty for
+	// effect parameters, which are stored in GlitchOptions.Params.
+	Field string
+	Kind  Kind
+	// Default is the default value in flag syntax.
+	Default string
+	// Range clamps numeric values; nil leaves them unbounded.
+	Range *Range
+	// Choices lists the valid values of a string option; nil allows any.
+	Choices []string
+	Help    string
+	Group   string
+	// AllEffects is the value -all-effects sets, in flag syntax. Empty
+	// leaves the option alone.
+	AllEffects string
+	// NoPreset keeps the option out of preset files.
+	NoPreset bool
+}
+
+// Schema lists every option in help order.
+var Schema = []*Option{
+	{Name: "fps", Field: "FPS", Kind: Int, Default: "30", Range: &Range{1, 240}, Group: "Core",
+		Help: "frames per second for the animation"},
+	{Name: "seed", Field: "Seed", Kind: Int, Default: "0", Group: "Core",
+		Help: "random seed for reproducible runs (0 picks one from the current time)"},
+	{Name: "intensity", Field: "Intensity", Kind: Int, Default: "5", Range: level, Group: "Core", AllEffects: "10",
+		Help: "glitch intensity"},
+	{Name: "bg", Field: "UseBG", Kind: Bool, Default: "false", Group: "Core", AllEffects: "true",
+		Help: "enable random background coloring"},
+	{Name: "strict", Field: "Strict", Kind: Bool, Default: "false", Group: "Core", NoPreset: true,
+		Help: "refuse to start if any option is invalid, out of range or conflicting"},
+	{Name: "all-effects", Field: "AllEffectsEnable", Kind: Bool, Default: "false", Group: "Core",
+		Help: "enable all glitch effects"},
+
+	{Name: "cp437", Field: "UseCP437", Kind: Bool, Default: "false", Group: "Character sets", AllEffects: "true",
+		Help: "use Code Page 437 characters for a retro effect"},
+	{Name: "blocks", Field: "UseBlocks", Kind: Bool, Default: "false", Group: "Character sets", AllEffects: "true",
+		Help: "use only block characters for a heavy glitch effect"},
+	{Name: "charset", Field: "Charset", Kind: String, Default: "", Group: "Character sets",
+		Help: "weighted charsets to draw characters from, e.g. \"ascii:1,katakana:3\"; overrides -cp437 and -blocks\n(built in: " + strings.Join(charset.Names, ", ") + ", custom, or a file in the charsets config directory)"},
+	{Name: "charset-custom", Field: "CharsetCustom", Kind: String, Default: "", Group: "Character sets",
+		Help: "characters of the \"custom\" charset"},
+
+	{Name: "theme", Field: "Theme", Kind: String, Default: "default", Group: "Colors",
+		Choices: theme.Names(),
+		Help:    "color theme"},
+	{Name: "palette", Field: "Palette", Kind: String, Default: "", Group: "Colors",
+		Help: "named palette to draw characters and cycle colors with instead of the theme's\n(built in: " + strings.Join(theme.BuiltinPaletteNames(), ", ") + ", or a file in the palettes config directory)"},
+
+	{Name: "color-mode", Field: "ColorMode", Kind: String, Default: "auto", Group: "Colors",
+		Choices: append([]string{"auto"}, frame.ColorModes...),
+		Help:    "colors to render with; auto detects what the terminal supports"},
+
+	{Name: "preset", Field: "Preset", Kind: String, Default: "", Group: "Presets", NoPreset: true,
+		Help: "load the named preset from the preset library (see \"glitch-saver preset\")"},
+	{Name: "save-preset", Field: "SavePreset", Kind: String, Default: "", Group: "Presets", NoPreset: true,
+		Help: "save the options that differ from the defaults to a file"},
+	{Name: "load-preset", Field: "LoadPreset", Kind: String, Default: "", Group: "Presets", NoPreset: true,
+		Help: "load options from a file"},
+
+	{Name: "char-corrupt", Field: "CharCorruptionEnable", Kind: Bool, Default: "true", Group: "Character corruption", AllEffects: "true",
+		Help: "enable character corruption glitch effect"},
+
+	{Name: "shift-line", Field: "ShiftLineEnable", Kind: Bool, Default: "false", Group: "Line shift", AllEffects: "true",
+		Help: "enable horizontal line shift glitch effect"},
+
+	{Name: "block-distort", Field: "BlockDistortionEnable", Kind: Bool, Default: "false", Group: "Block distortion", AllEffects: "true",
+		Help: "enable block distortion glitch effect"},
+
+	{Name: "scanline", Field: "ScanlineEnable", Kind: Bool, Default: "false", Group: "Scanline", AllEffects: "true",
+		Help: "enable scanline glitch effect"},
+	{Name: "scanline-prob", Field: "ScanlineProbability", Kind: Float, Default: "0.1", Range: probability, Group: "Scanline", AllEffects: "1",
+		Help: "probability of a scanline appearing each frame"},
+	{Name: "scanline-intensity", Field: "ScanlineIntensity", Kind: Int, Default: "5", Range: level, Group: "Scanline", AllEffects: "10",
+		Help: "intensity of scanlines"},
+	{Name: "scanline-char", Field: "ScanlineChar", Kind: String, Default: "", Group: "Scanline",
+		Help: "character to use for scanlines (default: random from current charSet)"},
+
+	{Name: "color-cycle", Field: "ColorCycleEnable", Kind: Bool, Default: "false", Group: "Color cycle", AllEffects: "true",
+		Help: "enable color cycling effect"},
+	{Name: "color-cycle-speed", Field: "ColorCycleSpeed", Kind: Int, Default: "5", Range: level, Group: "Color cycle", AllEffects: "10",
+		Help: "speed of color cycling"},
+
+	{Name: "smear", Field: "SmearEnable", Kind: Bool, Default: "false", Group: "Smear", AllEffects: "true",
+		Help: "enable character smearing/trails effect"},
+	{Name: "smear-prob", Field: "SmearProbability", Kind: Float, Default: "0.1", Range: probability, Group: "Smear", AllEffects: "1",
+		Help: "probability of a character starting to smear"},
+	{Name: "smear-length", Field: "SmearLength", Kind: Int, Default: "5", Range: frameCount, Group: "Smear", AllEffects: "10",
+		Help: "length of the smear trail (in frames)"},
+
+	// Static and scroll use high probabilities for -all-effects, but not
+	// 1.0, to leave room for the other effects.
+	{Name: "static", Field: "StaticEnable", Kind: Bool, Default: "false", Group: "Static", AllEffects: "true",
+		Help: "enable static burst effect"},
+	{Name: "static-prob", Field: "StaticProbability", Kind: Float, Default: "0.01", Range: probability, Group: "Static", AllEffects: "0.5",
+		Help: "probability of a static burst occurring each frame"},
+	{Name: "static-duration", Field: "StaticDuration", Kind: Int, Default: "3", Range: frameCount, Group: "Static", AllEffects: "5",
+		Help: "duration of a static burst (in frames)"},
+	{Name: "static-char", Field: "StaticChar", Kind: String, Default: "", Group: "Static",
+		Help: "character to use for static bursts (default: random from '. *')"},
+
+	{Name: "scroll", Field: "ScrollEnable", Kind: Bool, Default: "false", Group: "Scrolling blocks", AllEffects: "true",
+		Help: "enable scrolling blocks effect"},
+	{Name: "scroll-prob", Field: "ScrollProbability", Kind: Float, Default: "0.05", Range: probability, Group: "Scrolling blocks", AllEffects: "0.5",
+		Help: "probability of a new scrolling block appearing each frame"},
+	{Name: "scroll-speed", Field: "ScrollSpeed", Kind: Int, Default: "1", Range: frameCount, Group: "Scrolling blocks", AllEffects: "5",
+		Help: "speed of scrolling blocks"},
+	{Name: "scroll-direction", Field: "ScrollDirection", Kind: String, Default: "random", Group: "Scrolling blocks",
+		Choices: []string{"horizontal", "vertical", "random"},
+		Help:    "direction of scrolling blocks"},
+
+	{Name: "jitter", Field: "JitterEnable", Kind: Bool, Default: "false", Group: "Jitter", AllEffects: "true",
+		Help: "enable jitter effect"},
+	{Name: "jitter-prob", Field: "JitterProbability", Kind: Float, Default: "0.1", Range: probability, Group: "Jitter", AllEffects: "1",
+		Help: "probability of a character jittering"},
+
+	{Name: "melt", Field: "MeltEnable", Kind: Bool, Default: "false", Group: "Melt", AllEffects: "true",
+		Help: "enable melt effect"},
+	{Name: "melt-prob", Field: "MeltProbability", Kind: Float, Default: "0.1", Range: probability, Group: "Melt", AllEffects: "1",
+		Help: "probability of a character melting"},
+
+	{Name: "bitrot", Field: "BitRotEnable", Kind: Bool, Default: "false", Group: "Bit rot", AllEffects: "true",
+		Help: "enable bit-rot effect"},
+	{Name: "bitrot-prob", Field: "BitRotProbability", Kind: Float, Default: "0.1", Range: probability, Group: "Bit rot", AllEffects: "1",
+		Help: "probability of a character bit-rotting"},
+	{Name: "bitrot-charset", Field: "BitRotCharset", Kind: String, Default: "cp437", Group: "Bit rot",
+		Help: "charsets rotted characters are drawn from, in the format of -charset"},
+
+	{Name: "vert-line", Field: "VerticalLineEnable", Kind: Bool, Default: "false", Group: "Vertical line", AllEffects: "true",
+		Help: "enable vertical line glitch effect"},
+	{Name: "vert-line-prob", Field: "VerticalLineProbability", Kind: Float, Default: "0.1", Range: probability, Group: "Vertical line", AllEffects: "1",
+		Help: "probability of a vertical line appearing each frame"},
+
+	{Name: "invert-colors", Field: "InvertColorsEnable", Kind: Bool, Default: "false", Group: "Invert colors", AllEffects: "true",
+		Help: "enable invert colors glitch effect"},
+	{Name: "invert-colors-prob", Field: "InvertColorsProbability", Kind: Float, Default: "0.1", Range: probability, Group: "Invert colors", AllEffects: "1",
+		Help: "probability of a color inversion appearing each frame"},
+
+	{Name: "char-scramble", Field: "CharScrambleEnable", Kind: Bool, Default: "false", Group: "Character scramble", AllEffects: "true",
+		Help: "enable character scramble glitch effect"},
+	{Name: "char-scramble-prob", Field: "CharScrambleProbability", Kind: Float, Default: "0.1", Range: probability, Group: "Character scramble", AllEffects: "1",
+		Help: "probability of a character scramble appearing each frame"},
+
+	{Name: "ghosting", Field: "GhostingEnable", Kind: Bool, Default: "false", Group: "Ghosting", AllEffects: "true",
+		Help: "enable ghosting trail effect"},
+	{Name: "ghosting-prob", Field: "GhostingProbability", Kind: Float, Default: "0.1", Range: probability, Group: "Ghosting", AllEffects: "1",
+		Help: "probability of a character starting to ghost"},
+
+	{Name: "tunnel", Field: "TunnelEnable", Kind: Bool, Default: "false", Group: "Tunnel", AllEffects: "true",
+		Help: "enable tunnel/zoom effect"},
+	{Name: "tunnel-prob", Field: "TunnelProbability", Kind: Float, Default: "0.1", Range: probability, Group: "Tunnel", AllEffects: "0.5",
+		Help: "probability of a tunnel/zoom effect appearing each frame"},
+	{Name: "tunnel-speed", Field: "TunnelSpeed", Kind: Int, Default: "1", Range: level, Group: "Tunnel", AllEffects: "5",
+		Help: "speed of the tunnel/zoom effect"},
+}
+
+// effectParamsGroup is the help group of options registered with RegisterParam.
+const effectParamsGroup = "Effect parameters"
+
+func init() {
+	t := reflect.TypeOf(GlitchOptions{})
+	for _, opt := range Schema {
+		f, ok := t.FieldByName(opt.Field)
+		if !ok {
+			panic(fmt.Sprintf("options: schema option %q refers to unknown field %q", opt.Name, opt.Field))
+		}
+		if !kindMatches(opt.Kind, f.Type.Kind()) {
+			panic(fmt.Sprintf("options: schema option %q is a %s but field %s is a %s", opt.Name, opt.Kind, opt.Field, f.Type))
+		}
+	}
+}
+
+func kindMatches(k Kind, rk reflect.Kind) bool {
+	switch k {
+	case Bool:
+		return rk == reflect.Bool
+	case Int:
+		return rk == reflect.Int || rk == reflect.Int64
+	case Float:
+		return rk == reflect.Float64
+	default:
+		return rk == reflect.String
+	}
+}
+
+// Lookup returns the option with the given name, or nil if there is none.
+func Lookup(name string) *Option {
+	for _, opt := range Schema {
+		if opt.Name == name {
+			return opt
+		}
+	}
+	return nil
+}
+
+// Set parses value in flag syntax and stores it in o.
+func (opt *Option) Set(o *GlitchOptions, value string) error {
+	if err := opt.set(o, value); err != nil {
+		return fmt.Errorf("invalid value %q for %s: %v", value, opt.Name, err)
+	}
+	return nil
+}
+
+func (opt *Option) set(o *GlitchOptions, value string) error {
+	if opt.Field == "" {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return numError(err)
+		}
+		if o.Params == nil {
+			o.Params = make(map[string]float64)
+		}
+		o.Params[opt.Name] = v
+		return nil
+	}
+
+	f := opt.field(o)
+	switch opt.Kind {
+	case Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return numError(err)
+		}
+		f.SetBool(v)
+	case Int:
+		v, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			return numError(err)
+		}
+		f.SetInt(v)
+	case Float:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return numError(err)
+		}
+		f.SetFloat(v)
+	default:
+		f.SetString(value)
+	}
+	return nil
+}
+
+// Value returns the typed value of the option in o.
+func (opt *Option) Value(o *GlitchOptions) any {
+	if opt.Field == "" {
+		return o.Param(opt.Name)
+	}
+	return opt.field(o).Interface()
+}
+
+// Format returns the value of the option in o in flag syntax.
+func (opt *Option) Format(o *GlitchOptions) string {
+	switch v := opt.Value(o).(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// clamp limits the option in o to its range.
+func (opt *Option) clamp(o *GlitchOptions) {
+	if opt.Range == nil {
+		return
+	}
+	switch v := opt.Value(o).(type) {
+	case float64:
+		c := math.Max(opt.Range.Min, math.Min(opt.Range.Max, v))
+		if opt.Field == "" {
+			o.Params[opt.Name] = c
+		} else {
+			opt.field(o).SetFloat(c)
+		}
+	case int, int64:
+		n := opt.field(o).Int()
+		if float64(n) < opt.Range.Min {
+			opt.field(o).SetInt(int64(opt.Range.Min))
+		}
+		if float64(n) > opt.Range.Max {
+			opt.field(o).SetInt(int64(opt.Range.Max))
+		}
+	}
+}
+
+// Step changes the option in o by n steps and reports whether it can be
+// stepped at all: booleans toggle, choices cycle and numbers move by 1, or by
+// a twentieth of their range when it is narrower than 20, staying in range.
+// Free-form strings cannot be stepped.
+func (opt *Option) Step(o *GlitchOptions, n int) bool {
+	var value string
+	switch {
+	case opt.Kind == Bool:
+		value = strconv.FormatBool(opt.Value(o) != true)
+	case opt.Choices != nil:
+		i := slices.Index(opt.Choices, opt.Format(o)) + n
+		k := len(opt.Choices)
+		value = opt.Choices[(i%k+k)%k]
+	case opt.Kind == Int:
+		value = strconv.FormatInt(opt.field(o).Int()+int64(n), 10)
+	case opt.Kind == Float:
+		step := 1.0
+		if r := opt.Range; r != nil && r.Max-r.Min < 20 {
+			step = (r.Max - r.Min) / 20
+		}
+		v := opt.Value(o).(float64) + float64(n)*step
+		// Keep repeated steps from accumulating rounding errors
+		value = strconv.FormatFloat(math.Round(v*1e9)/1e9, 'g', -1, 64)
+	default:
+		return false
+	}
+	if err := opt.set(o, value); err != nil {
+		panic("options: bad step value: " + err.Error())
+	}
+	opt.clamp(o)
+	return true
+}
+
+func (opt *Option) field(o *GlitchOptions) reflect.Value {
+	return reflect.ValueOf(o).Elem().FieldByName(opt.Field)
+}
+
+// numError strips the function name from strconv errors, leaving "invalid
+// syntax" or "value out of range".
+func numError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
+	}
+	return err
+}
+
+// flagValue binds an option of a GlitchOptions to a command-line flag. It
+// remembers the text it was last set to, before any clamping.
+type flagValue struct {
+	opt  *Option
+	opts *GlitchOptions
+	raw  string
+}
+
+func (v *flagValue) String() string {
+	if v == nil || v.opt == nil || v.opts == nil {
+		return ""
+	}
+	return v.opt.Format(v.opts)
+}
+
+func (v *flagValue) Set(s string) error {
+	v.raw = s
+	return v.opt.set(v.opts, s)
+}
+
+func (v *flagValue) IsBoolFlag() bool { return v.opt != nil && v.opt.Kind == Bool }
@@ -0,0 +1,114 @@
+package options
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"glitch-saver/internal/charset"
+	"glitch-saver/internal/theme"
+)
+
+// Severity classifies an Issue.
+type Severity int
+
+const (
+	// Warning marks a valid but probably unintended combination of options.
+	Warning Severity = iota
+	// Error marks a value that cannot be used as given. Finalize replaces it
+	// with the nearest valid value.
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue is a problem with the options found by Validate.
+type Issue struct {
+	Severity Severity
+	// Option is the name of the option the issue is about.
+	Option string
+	// Source is where the option was set, e.g. a config file path. It is
+	// empty when unknown.
+	Source  string
+	Message string
+}
+
+func (i Issue) String() string {
+	s := fmt.Sprintf("%s: -%s: %s", i.Severity, i.Option, i.Message)
+	if i.Source != "" {
+		s += " (set in " + i.Source + ")"
+	}
+	return s
+}
+
+// Validate checks o for out-of-range values, values that are not among an
+// option's choices and conflicting options. It must be called before
+// Finalize, which silently corrects the errors it reports.
+func (o *GlitchOptions) Validate() []Issue {
+	var issues []Issue
+	add := func(sev Severity, opt, format string, args ...any) {
+		issues = append(issues, Issue{Severity: sev, Option: opt, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, opt := range Schema {
+		if r := opt.Range; r != nil {
+			v := opt.float(o)
+			switch {
+			case v < r.Min:
+				add(Error, opt.Name, "%s is below the minimum of %g; using %g", opt.Format(o), r.Min, r.Min)
+			case v > r.Max:
+				add(Error, opt.Name, "%s is above the maximum of %g; using %g", opt.Format(o), r.Max, r.Max)
+			}
+		}
+		if opt.Choices != nil {
+			if v := opt.Format(o); !slices.Contains(opt.Choices, v) {
+				add(Error, opt.Name, "%q is not one of %s; using %q", v, strings.Join(opt.Choices, ", "), opt.Default)
+			}
+		}
+		// An effect that is enabled but can never trigger is almost
+		// certainly a mistake
+		if opt.Kind == Bool && opt.Value(o) == true && !o.AllEffectsEnable {
+			if prob := Lookup(opt.Name + "-prob"); prob != nil && prob.float(o) == 0 {
+				add(Warning, opt.Name, "enabled but -%s is 0, so it never appears", prob.Name)
+			}
+		}
+	}
+
+	if o.Palette != "" {
+		if _, err := theme.LoadPalette(o.Palette); err != nil {
+			add(Error, "palette", "%v; using the theme's colors", err)
+		}
+	}
+	if o.Charset != "" {
+		if _, err := charset.Parse(o.Charset, o.CharsetCustom); err != nil {
+			add(Error, "charset", "%v; using the default characters", err)
+		} else if o.UseBlocks || o.UseCP437 {
+			add(Warning, "charset", "-charset overrides -blocks and -cp437")
+		}
+	}
+	if _, err := charset.Parse(o.BitRotCharset, o.CharsetCustom); err != nil {
+		add(Error, "bitrot-charset", "%v; using cp437", err)
+	}
+	if o.UseBlocks && o.UseCP437 && o.Charset == "" {
+		add(Warning, "blocks", "-blocks and -cp437 are both set; characters are drawn from both sets")
+	}
+	return issues
+}
+
+// float returns the value of a numeric option as a float64.
+func (opt *Option) float(o *GlitchOptions) float64 {
+	switch v := opt.Value(o).(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package preset
+
+import (
+	"embed"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// builtinFS holds the curated presets shipped with the binary. To add one,
+// drop a JSON file in the builtin directory.
+//
+//go:embed builtin/*.json
+var builtinFS embed.FS
+
+// Builtin returns the contents of the built-in preset with the given name.
+func Builtin(name string) ([]byte, bool) {
+	if CheckName(name) != nil {
+		return nil, false
+	}
+	data, err := builtinFS.ReadFile("builtin/" + name + ext)
+	return data, err == nil
+}
+
+// BuiltinNames returns the names of the built-in presets in sorted order.
+func BuiltinNames() []string {
+	entries, err := fs.ReadDir(builtinFS, "builtin")
+	if err != nil {
+		panic("preset: reading embedded presets: " + err.Error())
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ext))
+	}
+	sort.Strings(names)
+	return names
+}
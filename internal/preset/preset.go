@@ -0,0 +1,133 @@
+// Package preset manages the library of named presets. Each preset is a JSON
+// file in the presets directory of the config dir, holding only the options
+// that differ from their defaults. A set of curated presets is built in;
+// a stored preset with the same name takes precedence over a built-in one.
+package preset
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"glitch-saver/internal/config"
+	"glitch-saver/internal/options"
+)
+
+const ext = ".json"
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Store is a directory of preset files.
+type Store struct {
+	Dir string
+}
+
+// DefaultStore returns the store in the user's config directory.
+func DefaultStore() (*Store, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Dir: filepath.Join(dir, "presets")}, nil
+}
+
+// CheckName returns an error if name cannot be used as a preset name.
+func CheckName(name string) error {
+	if !validName.MatchString(name) || strings.HasSuffix(name, ext) {
+		return fmt.Errorf("invalid preset name %q: use letters, digits, '.', '-' and '_'", name)
+	}
+	return nil
+}
+
+// Path returns the file the named preset is stored in.
+func (s *Store) Path(name string) string {
+	return filepath.Join(s.Dir, name+ext)
+}
+
+// List returns the names of the stored presets in sorted order. It does not
+// include the built-in presets.
+func (s *Store) List() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ext)
+		if ok && !e.IsDir() && CheckName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Read returns the contents of the named preset, looking in the store first
+// and among the built-in presets second.
+func (s *Store) Read(name string) ([]byte, error) {
+	data, _, err := s.read(name)
+	return data, err
+}
+
+// read is Read that also returns where the preset was found.
+func (s *Store) read(name string) (data []byte, source string, err error) {
+	if err := CheckName(name); err != nil {
+		return nil, "", err
+	}
+	data, err = os.ReadFile(s.Path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		if data, ok := Builtin(name); ok {
+			return data, "built-in preset " + name, nil
+		}
+		return nil, "", fmt.Errorf("preset %q not found", name)
+	}
+	return data, s.Path(name), err
+}
+
+// Load reads the named preset as an options layer.
+func (s *Store) Load(name string) (options.Layer, error) {
+	data, source, err := s.read(name)
+	if err != nil {
+		return options.Layer{}, err
+	}
+	return options.PresetLayer(source, data)
+}
+
+// Save stores the options of opts that differ from the defaults under name,
+// replacing any preset with the same name.
+func (s *Store) Save(name string, opts *options.GlitchOptions) error {
+	if err := CheckName(name); err != nil {
+		return err
+	}
+	data, err := options.MarshalPreset(opts)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.Path(name), data, 0644)
+}
+
+// Delete removes the named preset from the store. Built-in presets cannot be
+// deleted.
+func (s *Store) Delete(name string) error {
+	if err := CheckName(name); err != nil {
+		return err
+	}
+	err := os.Remove(s.Path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		if _, ok := Builtin(name); ok {
+			return fmt.Errorf("preset %q is built in and cannot be deleted", name)
+		}
+		return fmt.Errorf("preset %q not found", name)
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package preset
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"glitch-saver/internal/options"
+)
+
+func TestStore(t *testing.T) {
+	s := &Store{Dir: t.TempDir() + "/presets"}
+	if names, err := s.List(); err != nil || names != nil {
+		t.Fatalf("List on a missing directory = %v, %v", names, err)
+	}
+
+	opts := options.Defaults()
+	opts.FPS = 12
+	opts.MeltEnable = true
+	opts.SavePreset = "ignored.json"
+	for _, name := range []string{"mine", "calm"} {
+		if err := s.Save(name, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if names, _ := s.List(); !reflect.DeepEqual(names, []string{"calm", "mine"}) {
+		t.Errorf("List = %v, want [calm mine]", names)
+	}
+
+	data, err := s.Read("mine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "{\n  \"fps\": 12,\n  \"melt\": true,\n  \"version\": 2\n}\n"; got != want {
+		t.Errorf("stored preset = %q, want only the non-default options %q", got, want)
+	}
+
+	layer, err := s.Load("mine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"fps": "12", "melt": "true"}
+	if !reflect.DeepEqual(layer.Values, want) {
+		t.Errorf("Load = %v, want %v", layer.Values, want)
+	}
+
+	if err := s.Delete("mine"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.Path("mine")); !os.IsNotExist(err) {
+		t.Errorf("preset file still exists after Delete: %v", err)
+	}
+	if err := s.Delete("mine"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Delete of a missing preset = %v, want a not found error", err)
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	for _, name := range []string{"vhs", "crt-calm", "v1.2_b"} {
+		if err := CheckName(name); err != nil {
+			t.Errorf("CheckName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../x", "a/b", ".hidden", "x.json"} {
+		if err := CheckName(name); err == nil {
+			t.Errorf("CheckName(%q) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestBuiltinPresets(t *testing.T) {
+	names := BuiltinNames()
+	if len(names) == 0 {
+		t.Fatal("no built-in presets")
+	}
+	for _, name := range names {
+		data, ok := Builtin(name)
+		if !ok {
+			t.Fatalf("Builtin(%q) not found", name)
+		}
+		if !strings.Contains(string(data), fmt.Sprintf(`"version": %d`, options.PresetVersion)) {
+			t.Errorf("%s: not at the current preset version", name)
+		}
+		layer, err := options.PresetLayer(name, data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, w := range layer.Warnings {
+			t.Errorf("%s: %s", name, w)
+		}
+		_, issues, err := options.Resolve(layer)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		for _, issue := range issues {
+			t.Errorf("%s: %s", name, issue)
+		}
+	}
+}
+
+func TestStoreShadowsBuiltin(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	builtin, _ := Builtin("vhs")
+	if data, err := s.Read("vhs"); err != nil || string(data) != string(builtin) {
+		t.Fatalf("Read(vhs) = %q, %v, want the built-in preset", data, err)
+	}
+	if err := s.Delete("vhs"); err == nil {
+		t.Error("Delete of a built-in preset succeeded")
+	}
+
+	opts := options.Defaults()
+	opts.FPS = 5
+	if err := s.Save("vhs", opts); err != nil {
+		t.Fatal(err)
+	}
+	layer, err := s.Load("vhs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"fps": "5"}; !reflect.DeepEqual(layer.Values, want) {
+		t.Errorf("Load(vhs) = %v, want the stored preset %v", layer.Values, want)
+	}
+}
@@ -0,0 +1,211 @@
+package theme
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+
+	"glitch-saver/internal/config"
+)
+
+// A palette file lists colors as #rrggbb, one per line:
+//
+//	# Dusk over the city.
+//	gradient 32
+//	#2d0b59
+//	#c2185b
+//	#ffc371
+//
+// Lines starting with # that are not colors are comments. Without a
+// gradient line the colors are used as listed; with "gradient N" they are
+// stops that are interpolated to N colors.
+const paletteExt = ".palette"
+
+// builtinPalettes holds the palettes shipped with the binary.
+//
+//go:embed palettes/*.palette
+var builtinPalettes embed.FS
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Palette is a named list of colors.
+type Palette struct {
+	Name   string
+	Colors []tcell.Color
+	// Gradient reports whether Colors were interpolated from gradient stops.
+	Gradient bool
+}
+
+// ParsePalette decodes a palette file. name is used in error messages and
+// as the palette's name.
+func ParsePalette(name string, data []byte) (*Palette, error) {
+	p := &Palette{Name: name}
+	var stops []tcell.Color
+	steps := 0
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case hexColor.MatchString(fields[0]):
+			if len(fields) > 1 && !strings.HasPrefix(fields[1], "#") {
+				return nil, fmt.Errorf("%s:%d: unexpected %q after color", name, lineNo, fields[1])
+			}
+			stops = append(stops, tcell.GetColor(fields[0]))
+		case strings.HasPrefix(fields[0], "#"):
+			// Comment
+		case fields[0] == "gradient" && len(fields) == 2:
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n < 2 {
+				return nil, fmt.Errorf("%s:%d: gradient needs a number of colors of at least 2", name, lineNo)
+			}
+			steps = n
+		default:
+			return nil, fmt.Errorf("%s:%d: expected a #rrggbb color or \"gradient N\"", name, lineNo)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(stops) == 0 {
+		return nil, fmt.Errorf("%s: palette has no colors", name)
+	}
+
+	p.Colors = stops
+	if steps > 0 {
+		p.Colors = Gradient(stops, steps)
+		p.Gradient = true
+	}
+	return p, nil
+}
+
+// Gradient interpolates n colors evenly spaced along the stops.
+func Gradient(stops []tcell.Color, n int) []tcell.Color {
+	if len(stops) == 1 || n < 2 {
+		out := make([]tcell.Color, n)
+		for i := range out {
+			out[i] = stops[0]
+		}
+		return out
+	}
+	out := make([]tcell.Color, n)
+	for i := range out {
+		pos := float64(i) * float64(len(stops)-1) / float64(n-1)
+		j := int(pos)
+		if j >= len(stops)-1 {
+			out[i] = stops[len(stops)-1]
+			continue
+		}
+		out[i] = mix(stops[j], stops[j+1], pos-float64(j))
+	}
+	return out
+}
+
+// mix returns the color a fraction t of the way from a to b.
+func mix(a, b tcell.Color, t float64) tcell.Color {
+	ar, ag, ab := a.RGB()
+	br, bg, bb := b.RGB()
+	lerp := func(x, y int32) int32 { return x + int32(float64(y-x)*t+0.5) }
+	return tcell.NewRGBColor(lerp(ar, br), lerp(ag, bg), lerp(ab, bb))
+}
+
+// CycleColors returns the sequence color cycling steps through. A gradient
+// is run forwards and then backwards so that wrapping around is seamless.
+func (p *Palette) CycleColors() []tcell.Color {
+	if !p.Gradient || len(p.Colors) < 3 {
+		return p.Colors
+	}
+	out := append([]tcell.Color(nil), p.Colors...)
+	for i := len(p.Colors) - 2; i > 0; i-- {
+		out = append(out, p.Colors[i])
+	}
+	return out
+}
+
+// PaletteDir returns the directory user palette files are loaded from.
+func PaletteDir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "palettes"), nil
+}
+
+// LoadPalette loads the named palette from the user's palette directory or,
+// failing that, from the built-in palettes.
+func LoadPalette(name string) (*Palette, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid palette name %q", name)
+	}
+	if dir, err := PaletteDir(); err == nil {
+		path := filepath.Join(dir, name+paletteExt)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			p, err := ParsePalette(path, data)
+			if p != nil {
+				p.Name = name
+			}
+			return p, err
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	data, err := builtinPalettes.ReadFile("palettes/" + name + paletteExt)
+	if err != nil {
+		return nil, fmt.Errorf("palette %q not found", name)
+	}
+	return ParsePalette(name, data)
+}
+
+// BuiltinPaletteNames returns the names of the built-in palettes in sorted
+// order.
+func BuiltinPaletteNames() []string {
+	entries, err := fs.ReadDir(builtinPalettes, "palettes")
+	if err != nil {
+		panic("theme: reading embedded palettes: " + err.Error())
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), paletteExt))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the named theme with its foreground and cycling colors
+// replaced by the named palette, if any. When the theme or palette cannot be
+// found it returns the best available theme along with the error.
+func Resolve(name, palette string) (*Theme, error) {
+	t, ok := Lookup(name)
+	if !ok {
+		return Default, fmt.Errorf("unknown theme %q", name)
+	}
+	if palette == "" {
+		return t, nil
+	}
+	p, err := LoadPalette(palette)
+	if err != nil {
+		return t, err
+	}
+	return &Theme{
+		Name:   t.Name + "+" + p.Name,
+		Fg:     p.Colors,
+		Bg:     t.Bg,
+		Static: t.Static,
+		Cycle:  p.CycleColors(),
+	}, nil
+}
@@ -0,0 +1,100 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParsePalette(t *testing.T) {
+	p, err := ParsePalette("test", []byte("# comment\n#ff0000 # red\n\n#0000ff\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []tcell.Color{tcell.NewRGBColor(255, 0, 0), tcell.NewRGBColor(0, 0, 255)}
+	if !reflect.DeepEqual(p.Colors, want) || p.Gradient {
+		t.Errorf("ParsePalette = %+v, want colors %v", p, want)
+	}
+
+	for _, data := range []string{"", "# only a comment", "#ff000", "red", "gradient 1\n#ff0000", "#ff0000 red"} {
+		if _, err := ParsePalette("test", []byte(data)); err == nil {
+			t.Errorf("ParsePalette(%q) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestGradient(t *testing.T) {
+	got := Gradient([]tcell.Color{tcell.NewRGBColor(0, 0, 0), tcell.NewRGBColor(200, 100, 0), tcell.NewRGBColor(200, 200, 200)}, 5)
+	want := []tcell.Color{
+		tcell.NewRGBColor(0, 0, 0),
+		tcell.NewRGBColor(100, 50, 0),
+		tcell.NewRGBColor(200, 100, 0),
+		tcell.NewRGBColor(200, 150, 100),
+		tcell.NewRGBColor(200, 200, 200),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Gradient = %v, want %v", got, want)
+	}
+}
+
+func TestCycleColors(t *testing.T) {
+	p, err := ParsePalette("test", []byte("gradient 4\n#000000\n#ffffff\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := p.Colors
+	if got, want := p.CycleColors(), []tcell.Color{c[0], c[1], c[2], c[3], c[2], c[1]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CycleColors = %v, want %v", got, want)
+	}
+}
+
+func TestLoadPalette(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	for _, name := range BuiltinPaletteNames() {
+		if _, err := LoadPalette(name); err != nil {
+			t.Errorf("built-in palette %s: %v", name, err)
+		}
+	}
+
+	// A user palette shadows the built-in one
+	if err := os.MkdirAll(filepath.Join(dir, "glitch-saver", "palettes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "glitch-saver", "palettes", "fire.palette"), []byte("#123456\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPalette("fire")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "fire" || len(p.Colors) != 1 || p.Colors[0] != tcell.NewRGBColor(0x12, 0x34, 0x56) {
+		t.Errorf("LoadPalette(fire) = %+v, want the user palette", p)
+	}
+
+	for _, name := range []string{"nope", "../fire", ""} {
+		if _, err := LoadPalette(name); err == nil {
+			t.Errorf("LoadPalette(%q) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	th, err := Resolve("matrix", "sunset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrix, _ := Lookup("matrix")
+	sunset, _ := LoadPalette("sunset")
+	if !reflect.DeepEqual(th.Fg, sunset.Colors) || !reflect.DeepEqual(th.Bg, matrix.Bg) || len(th.Cycle) != 2*len(sunset.Colors)-2 {
+		t.Errorf("Resolve(matrix, sunset) = %+v", th)
+	}
+	if th, err := Resolve("nope", ""); err == nil || th != Default {
+		t.Errorf("Resolve of an unknown theme = %v, %v; want the default theme and an error", th, err)
+	}
+}
@@ -0,0 +1,98 @@
+// Package theme defines the named color palettes the effects draw with.
+package theme
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme is a named set of palettes. Every palette holds at least one color.
+type Theme struct {
+	Name string
+	// Fg colors the characters drawn by the glitch effects.
+	Fg []tcell.Color
+	// Bg colors cell backgrounds when -bg is set.
+	Bg []tcell.Color
+	// Static colors the characters and backgrounds of static bursts.
+	Static []tcell.Color
+	// Cycle is the sequence of colors that color-cycling cells step through.
+	Cycle []tcell.Color
+}
+
+// Default is the theme used when none is selected: the eight primary
+// terminal colors on black, white and grey.
+var Default = &Theme{
+	Name:   "default",
+	Fg:     primaries,
+	Bg:     primaries,
+	Static: hex("#000000", "#808080", "#ffffff"),
+	Cycle:  primaries,
+}
+
+var primaries = hex(
+	"#000000", // Black
+	"#ff0000", // Red
+	"#00ff00", // Green
+	"#ffff00", // Yellow
+	"#0000ff", // Blue
+	"#ff00ff", // Magenta
+	"#00ffff", // Cyan
+	"#ffffff", // White
+)
+
+var themes = []*Theme{
+	Default,
+	{
+		Name:   "matrix",
+		Fg:     hex("#003b00", "#008f11", "#00c21a", "#00ff41", "#7dff9a", "#d4ffd9"),
+		Bg:     hex("#000000", "#000000", "#001a00", "#003b00"),
+		Static: hex("#000000", "#0d3d0d", "#00ff41"),
+		Cycle:  hex("#003b00", "#005a0a", "#008f11", "#00c21a", "#00ff41", "#7dff9a", "#00ff41", "#00c21a", "#008f11", "#005a0a"),
+	},
+	{
+		Name:   "vaporwave",
+		Fg:     hex("#ff71ce", "#01cdfe", "#05ffa1", "#b967ff", "#fffb96"),
+		Bg:     hex("#1a0933", "#2d1b69", "#3b0f4f", "#000000"),
+		Static: hex("#2d1b69", "#ff71ce", "#01cdfe"),
+		Cycle:  hex("#ff71ce", "#d46fe8", "#b967ff", "#5e9cfe", "#01cdfe", "#05ffa1", "#fffb96", "#ffb0a8"),
+	},
+	{
+		Name:   "grayscale",
+		Fg:     hex("#3a3a3a", "#606060", "#808080", "#a8a8a8", "#d0d0d0", "#ffffff"),
+		Bg:     hex("#000000", "#1c1c1c", "#303030", "#444444"),
+		Static: hex("#000000", "#808080", "#ffffff"),
+		Cycle:  hex("#303030", "#4e4e4e", "#6c6c6c", "#8a8a8a", "#a8a8a8", "#c6c6c6", "#e4e4e4", "#ffffff"),
+	},
+}
+
+// Names returns the names of the built-in themes, the default first.
+func Names() []string {
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Lookup returns the built-in theme with the given name.
+func Lookup(name string) (*Theme, bool) {
+	for _, t := range themes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// hex converts "#rrggbb" strings to colors. It panics on malformed input and
+// is only meant for the palettes declared in this package.
+func hex(colors ...string) []tcell.Color {
+	out := make([]tcell.Color, len(colors))
+	for i, s := range colors {
+		c := tcell.GetColor(s)
+		if c == tcell.ColorDefault {
+			panic("theme: bad color " + s)
+		}
+		out[i] = c
+	}
+	return out
+}
@@ -0,0 +1,29 @@
+package theme
+
+import "testing"
+
+func TestThemes(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, name := range Names() {
+		if seen[name] {
+			t.Errorf("theme %q is declared twice", name)
+		}
+		seen[name] = true
+
+		th, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("Lookup(%q) failed", name)
+		}
+		for palette, colors := range map[string]int{"Fg": len(th.Fg), "Bg": len(th.Bg), "Static": len(th.Static), "Cycle": len(th.Cycle)} {
+			if colors == 0 {
+				t.Errorf("theme %q has an empty %s palette", name, palette)
+			}
+		}
+	}
+	if Names()[0] != Default.Name {
+		t.Errorf("first theme is %q, want the default", Names()[0])
+	}
+	if _, ok := Lookup("nope"); ok {
+		t.Error("Lookup of an unknown theme succeeded")
+	}
+}
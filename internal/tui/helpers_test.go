@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"glitch-saver/internal/effects"
+	"glitch-saver/internal/options"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// newTestRunner returns a runner on a simulation screen of the given size.
+func newTestRunner(t *testing.T, width, height int, args ...string) *runner {
+	t.Helper()
+	opts, err := options.ParseArgs(flag.NewFlagSet("test", flag.ContinueOnError), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := tcell.NewSimulationScreen("UTF-8")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Fini)
+	s.SetSize(width, height)
+	engine := effects.NewEngine(opts)
+	engine.Resize(width, height)
+	ticker := time.NewTicker(time.Hour)
+	t.Cleanup(ticker.Stop)
+	r := &runner{screen: s, engine: engine, opts: opts, ticker: ticker, panel: newPanel()}
+	r.updateColorMode()
+	return r
+}
+
+// screenText returns the characters on a row of the screen.
+func screenText(s tcell.Screen, y int) string {
+	width, _ := s.Size()
+	var b strings.Builder
+	for x := 0; x < width; x++ {
+		str, _, _ := s.Get(x, y)
+		b.WriteString(str)
+	}
+	return b.String()
+}
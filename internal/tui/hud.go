@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"glitch-saver/internal/options"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+// hudWidth is the widest the heads-up display gets, in columns.
+const hudWidth = 48
+
+// hud is the heads-up display showing the measured frame rate and render
+// time, the screen size, the seed and the enabled effects. It is drawn on the
+// screen after the grid has been flushed, so the effects never read it back
+// and cannot smear it across the screen.
+type hud struct {
+	visible bool
+	// frames holds the times of the frames rendered in the last second.
+	frames []time.Time
+	// render is the moving average of the time taken to render a frame.
+	render time.Duration
+}
+
+// frame records a frame that started at start and took d to render.
+func (h *hud) frame(start time.Time, d time.Duration) {
+	cutoff := start.Add(-time.Second)
+	i := 0
+	for i < len(h.frames) && !h.frames[i].After(cutoff) {
+		i++
+	}
+	h.frames = append(h.frames[i:], start)
+	if h.render == 0 {
+		h.render = d
+	} else {
+		h.render += (d - h.render) / 10
+	}
+}
+
+// lines returns the text of the display.
+func (h *hud) lines(r *runner) []string {
+	now := time.Now()
+	fps := 0
+	for _, t := range h.frames {
+		if now.Sub(t) <= time.Second {
+			fps++
+		}
+	}
+	width, height := r.screen.Size()
+	lines := []string{
+		fmt.Sprintf("fps    %d / %d", fps, r.opts.FPS),
+		fmt.Sprintf("frame  %.2fms", float64(h.render.Microseconds())/1000),
+		fmt.Sprintf("size   %dx%d", width, height),
+		fmt.Sprintf("seed   %d", r.opts.Seed),
+	}
+
+	// List the effects with their probability where they have one, wrapped
+	// to the width of the display
+	var names []string
+	for _, name := range r.engine.Enabled() {
+		if opt := options.Lookup(name + "-prob"); opt != nil {
+			name += " " + opt.Format(r.opts)
+		}
+		names = append(names, name)
+	}
+	if names == nil {
+		names = []string{"none"}
+	}
+	line := "effects"
+	for i, name := range names {
+		if i > 0 {
+			line += ","
+		}
+		if uniseg.StringWidth(line)+1+uniseg.StringWidth(name) > hudWidth-2 {
+			lines = append(lines, line)
+			line = "      "
+		}
+		line += " " + name
+	}
+	return append(lines, line)
+}
+
+// draw draws the display over the top-left corner of the screen.
+func (h *hud) draw(r *runner) {
+	if !h.visible {
+		return
+	}
+	drawBox(r.screen, h.lines(r))
+}
+
+// drawBox draws lines from the top-left corner of the screen, padded with a
+// space on either side to the width of the widest line.
+func drawBox(s tcell.Screen, lines []string) {
+	w := 0
+	for _, l := range lines {
+		w = max(w, uniseg.StringWidth(l))
+	}
+	style := tcell.StyleDefault
+	for y, l := range lines {
+		x := drawText(s, 0, y, " "+l, style)
+		drawText(s, x, y, strings.Repeat(" ", w+2-x), style)
+	}
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestHUD(t *testing.T) {
+	r := newTestRunner(t, 60, 10, "-melt", "-melt-prob", "0.3", "-seed", "7", "-fps", "20")
+	s, engine := r.screen, r.engine
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		r.hud.frame(now.Add(time.Duration(i-3)*time.Millisecond), 2*time.Millisecond)
+	}
+	got := strings.Join(r.hud.lines(r), "\n")
+	for _, want := range []string{"fps    3 / 20", "frame  2.00ms", "size   60x10", "seed   7", "effects char-corrupt, melt 0.3"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HUD does not show %q:\n%s", want, got)
+		}
+	}
+
+	// The display goes on the screen only, leaving the grid to the effects
+	engine.DrawGlitch()
+	before := engine.Grid().Get(1, 0)
+	r.hud.visible = true
+	engine.Grid().Flush(s)
+	r.hud.draw(r)
+	s.Show()
+	if c := engine.Grid().Get(1, 0); c.Rune != before.Rune {
+		t.Errorf("drawing the HUD changed the grid")
+	}
+	if str, _, _ := s.Get(1, 0); str != "f" {
+		t.Errorf("screen shows %q at the HUD, want %q", str, "f")
+	}
+}
+
+func TestDrawBox(t *testing.T) {
+	r := newTestRunner(t, 20, 3)
+	r.screen.Fill('.', tcell.StyleDefault)
+	drawBox(r.screen, []string{"set ア…", "x"})
+	r.screen.Show()
+
+	// Columns follow the width of the text, and every line is padded to
+	// the width of the widest one
+	for _, tc := range []struct {
+		x, y int
+		want string
+	}{
+		{1, 0, "s"}, {5, 0, "ア"}, {7, 0, "…"}, {8, 0, " "},
+		{1, 1, "x"}, {8, 1, " "}, {9, 1, "."},
+	} {
+		if str, _, _ := r.screen.Get(tc.x, tc.y); str != tc.want {
+			t.Errorf("column %d of row %d shows %q, want %q", tc.x, tc.y, str, tc.want)
+		}
+	}
+}
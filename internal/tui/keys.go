@@ -0,0 +1,420 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"glitch-saver/internal/effects"
+	"glitch-saver/internal/frame"
+	"glitch-saver/internal/options"
+	"glitch-saver/internal/preset"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+// Key identifies a key press: a special key such as tcell.KeyF5 with its
+// modifiers, or a character for tcell.KeyRune.
+type Key struct {
+	Key  tcell.Key
+	Rune rune
+	Mod  tcell.ModMask
+}
+
+// keyOf returns the Key of a key event. Characters are matched without the
+// shift modifier, which is already part of the character typed.
+func keyOf(ev *tcell.EventKey) Key {
+	if ev.Key() == tcell.KeyRune {
+		return Key{Key: tcell.KeyRune, Rune: ev.Rune(), Mod: ev.Modifiers() &^ tcell.ModShift}
+	}
+	return Key{Key: ev.Key(), Mod: ev.Modifiers()}
+}
+
+// runeKey returns the Key typed as r.
+func runeKey(r rune) Key {
+	return Key{Key: tcell.KeyRune, Rune: r}
+}
+
+// modifiers lists the modifier names of key specs in the order they are
+// written.
+var modifiers = []struct {
+	name string
+	mod  tcell.ModMask
+}{
+	{"ctrl", tcell.ModCtrl},
+	{"alt", tcell.ModAlt},
+	{"meta", tcell.ModMeta},
+	{"shift", tcell.ModShift},
+}
+
+// keyNames maps the lower case names of special keys to their keys.
+var keyNames = func() map[string]tcell.Key {
+	names := map[string]tcell.Key{"escape": tcell.KeyEscape}
+	for k, name := range tcell.KeyNames {
+		// Control keys are written as ctrl+ followed by the character
+		if !strings.HasPrefix(name, "Ctrl-") {
+			names[strings.ToLower(name)] = k
+		}
+	}
+	return names
+}()
+
+// ParseKey parses a key spec: a character, "space" or the name of a special
+// key such as "F5", "up" or "esc", optionally preceded by modifiers, as in
+// "ctrl+p" or "shift+up". Names are case insensitive, characters are not.
+func ParseKey(spec string) (Key, error) {
+	var mod tcell.ModMask
+	rest := spec
+	for {
+		i := strings.Index(rest, "+")
+		if i <= 0 || i == len(rest)-1 {
+			break
+		}
+		found := false
+		for _, m := range modifiers {
+			if strings.EqualFold(rest[:i], m.name) {
+				mod |= m.mod
+				found = true
+			}
+		}
+		if !found {
+			return Key{}, fmt.Errorf("invalid key %q: unknown modifier %q", spec, rest[:i])
+		}
+		rest = rest[i+1:]
+	}
+
+	if r, size := utf8.DecodeRuneInString(rest); size == len(rest) && r != utf8.RuneError {
+		if mod&tcell.ModShift != 0 {
+			r = unicode.ToUpper(r)
+		}
+		return keyOf(tcell.NewEventKey(tcell.KeyRune, r, mod)), nil
+	}
+	name := strings.ToLower(rest)
+	if name == "space" {
+		if mod == tcell.ModCtrl {
+			return Key{Key: tcell.KeyCtrlSpace, Mod: tcell.ModCtrl}, nil
+		}
+		return keyOf(tcell.NewEventKey(tcell.KeyRune, ' ', mod)), nil
+	}
+	k, ok := keyNames[name]
+	if !ok {
+		return Key{}, fmt.Errorf("invalid key %q: unknown key name %q", spec, rest)
+	}
+	return keyOf(tcell.NewEventKey(k, 0, mod)), nil
+}
+
+// String returns the key in the syntax of ParseKey.
+func (k Key) String() string {
+	var b strings.Builder
+	mod := k.Mod
+	if k.Key >= tcell.KeyCtrlSpace && k.Key <= tcell.KeyCtrlZ {
+		mod |= tcell.ModCtrl
+	}
+	for _, m := range modifiers {
+		if mod&m.mod != 0 {
+			b.WriteString(m.name + "+")
+		}
+	}
+	switch {
+	case k.Key == tcell.KeyRune && k.Rune == ' ', k.Key == tcell.KeyCtrlSpace:
+		b.WriteString("space")
+	case k.Key == tcell.KeyRune:
+		b.WriteRune(k.Rune)
+	case k.Key > tcell.KeyCtrlSpace && k.Key <= tcell.KeyCtrlZ:
+		b.WriteRune(rune('a' + k.Key - tcell.KeyCtrlA))
+	default:
+		name, ok := tcell.KeyNames[k.Key]
+		if !ok {
+			name = fmt.Sprintf("key%d", k.Key)
+		}
+		b.WriteString(strings.ToLower(name))
+	}
+	return b.String()
+}
+
+// Keymap binds keys to the names of actions.
+type Keymap map[Key]string
+
+// Bind replaces the keys bound to an action with the keys in specs, a list
+// of key specs separated by spaces. Keys bound to other actions are taken
+// over; "none" leaves the action unbound.
+func (km Keymap) Bind(action, specs string) error {
+	if _, ok := LookupAction(action); !ok {
+		return fmt.Errorf("unknown action %q", action)
+	}
+	var keys []Key
+	for _, spec := range strings.Fields(specs) {
+		if spec == "none" {
+			continue
+		}
+		k, err := ParseKey(spec)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, k)
+	}
+	for k, a := range km {
+		if a == action {
+			delete(km, k)
+		}
+	}
+	for _, k := range keys {
+		km[k] = action
+	}
+	return nil
+}
+
+// Apply binds the keys of every action in bindings, which maps action names
+// to key specs as accepted by Bind. It is an error to bind a key to more
+// than one action.
+func (km Keymap) Apply(bindings map[string]string) error {
+	actions := slices.Sorted(maps.Keys(bindings))
+	bound := make(map[Key]string)
+	for _, action := range actions {
+		if err := km.Bind(action, bindings[action]); err != nil {
+			return fmt.Errorf("keys.%s: %v", action, err)
+		}
+		for _, k := range km.Keys(action) {
+			if other, ok := bound[k]; ok {
+				return fmt.Errorf("keys.%s: %s is already bound to %s", action, k, other)
+			}
+			bound[k] = action
+		}
+	}
+	return nil
+}
+
+// Keys returns the keys bound to an action, sorted by their spec.
+func (km Keymap) Keys(action string) []Key {
+	var keys []Key
+	for k, a := range km {
+		if a == action {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b Key) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return keys
+}
+
+// effectKeys are bound to toggling the effects in pipeline order.
+var effectKeys = []rune("1234567890!@#$%^")
+
+// DefaultKeymap returns the built-in key bindings.
+func DefaultKeymap() Keymap {
+	km := Keymap{
+		{Key: tcell.KeyEscape}: "quit",
+		runeKey('q'):           "quit",
+		runeKey(' '):           "pause",
+		runeKey('r'):           "reseed",
+		runeKey('s'):           "save-preset",
+		runeKey('h'):           "toggle-hud",
+		{Key: tcell.KeyTab}:    "settings",
+		runeKey('+'):           "intensity-up",
+		runeKey('='):           "intensity-up",
+		runeKey('-'):           "intensity-down",
+		runeKey(']'):           "fps-up",
+		runeKey('['):           "fps-down",
+	}
+	i := 0
+	for _, a := range Actions() {
+		if a.effect != "" && i < len(effectKeys) {
+			km[runeKey(effectKeys[i])] = a.Name
+			i++
+		}
+	}
+	return km
+}
+
+// Action is something a key can be bound to while the saver runs.
+type Action struct {
+	Name string
+	Help string
+
+	// effect is the effect toggled by the action, if any.
+	effect string
+	run    func(r *runner)
+}
+
+// fpsStep is the number of frames per second fps-up and fps-down change.
+const fpsStep = 5
+
+// Actions returns every action in the order they are documented: the
+// built-in actions followed by toggling each effect that can be enabled with
+// an option of the same name, in pipeline order.
+func Actions() []Action {
+	actions := []Action{
+		{Name: "quit", Help: "exit the screensaver", run: func(r *runner) { r.quit = true }},
+		{Name: "pause", Help: "pause or resume the animation", run: (*runner).togglePause},
+		{Name: "reseed", Help: "restart the random number generator with a new seed", run: (*runner).reseed},
+		{Name: "save-preset", Help: "save the current options as a new preset", run: (*runner).savePreset},
+		{Name: "settings", Help: "open the settings panel", run: func(r *runner) { r.panel.open = true }},
+		{Name: "toggle-hud", Help: "show or hide the frame rate, seed and enabled effects", run: func(r *runner) { r.hud.visible = !r.hud.visible }},
+		{Name: "intensity-up", Help: "increase the intensity", run: func(r *runner) { r.step("intensity", 1) }},
+		{Name: "intensity-down", Help: "decrease the intensity", run: func(r *runner) { r.step("intensity", -1) }},
+		{Name: "fps-up", Help: "increase the frame rate", run: func(r *runner) { r.step("fps", fpsStep) }},
+		{Name: "fps-down", Help: "decrease the frame rate", run: func(r *runner) { r.step("fps", -fpsStep) }},
+	}
+	for _, name := range effects.Names() {
+		if opt := options.Lookup(name); opt == nil || opt.Kind != options.Bool {
+			continue
+		}
+		actions = append(actions, Action{
+			Name:   "toggle-" + name,
+			Help:   "turn the " + name + " effect on or off",
+			effect: name,
+			run:    func(r *runner) { r.step(name, 1) },
+		})
+	}
+	return actions
+}
+
+// LookupAction returns the action with the given name.
+func LookupAction(name string) (Action, bool) {
+	for _, a := range Actions() {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Action{}, false
+}
+
+// runner holds the state of a running screensaver that actions change.
+// Actions run on the same goroutine that draws the frames, so they can
+// change the live options without further synchronization.
+type runner struct {
+	screen tcell.Screen
+	engine *effects.Engine
+	opts   *options.GlitchOptions
+	ticker *time.Ticker
+	mode   frame.ColorMode
+	paused bool
+	quit   bool
+	hud    hud
+	panel  panel
+
+	// status is a message shown at the bottom of the screen until
+	// statusUntil.
+	status      string
+	statusUntil time.Time
+}
+
+// statusDuration is how long the message of an action stays on screen.
+const statusDuration = 2 * time.Second
+
+// setStatus shows a message about the last action.
+func (r *runner) setStatus(format string, args ...any) {
+	r.status = fmt.Sprintf(format, args...)
+	r.statusUntil = time.Now().Add(statusDuration)
+}
+
+// drawStatus draws the current message, if any, over the bottom row.
+func (r *runner) drawStatus() {
+	msg := r.status
+	if time.Now().After(r.statusUntil) {
+		msg = ""
+	}
+	if r.paused {
+		msg = "paused " + msg
+	}
+	if msg == "" {
+		return
+	}
+	_, height := r.screen.Size()
+	drawText(r.screen, 0, height-1, " "+msg+" ", tcell.StyleDefault.Reverse(true))
+}
+
+// drawText draws text on the screen starting at column x, one character per
+// grapheme cluster, and returns the column after it. Wide characters take up
+// two columns.
+func drawText(s tcell.Screen, x, y int, text string, style tcell.Style) int {
+	state := -1
+	for text != "" {
+		var cluster string
+		var width int
+		cluster, text, width, state = uniseg.FirstGraphemeClusterInString(text, state)
+		runes := []rune(cluster)
+		s.SetContent(x, y, runes[0], runes[1:], style)
+		x += width
+	}
+	return x
+}
+
+// step changes an option by n steps and shows its new value.
+func (r *runner) step(name string, n int) {
+	opt := options.Lookup(name)
+	r.apply(opt, n)
+	switch {
+	case opt.Kind != options.Bool:
+		r.setStatus("%s %s", name, opt.Format(r.opts))
+	case opt.Value(r.opts) == true:
+		r.setStatus("%s on", name)
+	default:
+		r.setStatus("%s off", name)
+	}
+}
+
+// apply changes an option by n steps and brings the parts of the running
+// screensaver that do not read the options every frame up to date.
+func (r *runner) apply(opt *options.Option, n int) {
+	if !opt.Step(r.opts, n) {
+		return
+	}
+	switch opt.Name {
+	case "fps":
+		r.ticker.Reset(time.Second / time.Duration(r.opts.FPS))
+	case "seed":
+		r.engine.Reseed(r.opts.Seed)
+	case "color-mode":
+		r.updateColorMode()
+	}
+}
+
+// updateColorMode maps colors to what the terminal can display, as selected
+// by -color-mode.
+func (r *runner) updateColorMode() {
+	mode, ok := frame.ParseColorMode(r.opts.ColorMode)
+	if !ok {
+		mode = frame.DetectColorMode(r.screen.Colors(), os.Getenv("COLORTERM"))
+	}
+	r.mode = mode
+	r.engine.SetColorMode(mode)
+}
+
+func (r *runner) togglePause() {
+	r.paused = !r.paused
+	r.status = ""
+}
+
+func (r *runner) reseed() {
+	r.opts.Seed = time.Now().UnixNano()
+	r.engine.Reseed(r.opts.Seed)
+	r.setStatus("seed %d", r.opts.Seed)
+}
+
+// savePreset stores the live options in the preset library under a name
+// made from the current time.
+func (r *runner) savePreset() {
+	store, err := preset.DefaultStore()
+	if err != nil {
+		r.setStatus("cannot save preset: %v", err)
+		return
+	}
+	name := "live-" + time.Now().Format("20060102-150405")
+	// -all-effects has already been applied to the options, and would
+	// override any effect turned off since
+	live := *r.opts
+	live.AllEffectsEnable = false
+	if err := store.Save(name, &live); err != nil {
+		r.setStatus("cannot save preset: %v", err)
+		return
+	}
+	r.setStatus("saved preset %s", name)
+}
@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"glitch-saver/internal/options"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseKey(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		want Key
+		str  string
+	}{
+		{"q", runeKey('q'), "q"},
+		{"Q", runeKey('Q'), "Q"},
+		{"+", runeKey('+'), "+"},
+		{"space", runeKey(' '), "space"},
+		{"ctrl+p", Key{Key: tcell.KeyCtrlP, Mod: tcell.ModCtrl}, "ctrl+p"},
+		{"Ctrl+P", Key{Key: tcell.KeyCtrlP, Mod: tcell.ModCtrl}, "ctrl+p"},
+		{"ctrl++", Key{Key: tcell.KeyRune, Rune: '+', Mod: tcell.ModCtrl}, "ctrl++"},
+		{"alt+x", Key{Key: tcell.KeyRune, Rune: 'x', Mod: tcell.ModAlt}, "alt+x"},
+		{"shift+a", runeKey('A'), "A"},
+		{"F5", Key{Key: tcell.KeyF5}, "f5"},
+		{"shift+up", Key{Key: tcell.KeyUp, Mod: tcell.ModShift}, "shift+up"},
+		{"esc", Key{Key: tcell.KeyEscape}, "esc"},
+		{"escape", Key{Key: tcell.KeyEscape}, "esc"},
+	} {
+		got, err := ParseKey(tc.spec)
+		if err != nil {
+			t.Errorf("ParseKey(%q): %v", tc.spec, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseKey(%q) = %+v, want %+v", tc.spec, got, tc.want)
+		}
+		if s := got.String(); s != tc.str {
+			t.Errorf("ParseKey(%q).String() = %q, want %q", tc.spec, s, tc.str)
+		}
+	}
+
+	for _, spec := range []string{"", "hyperdrive", "ctrl+", "super+a", "ctrl+nokey"} {
+		if _, err := ParseKey(spec); err == nil {
+			t.Errorf("ParseKey(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestKeyOfMatchesParseKey(t *testing.T) {
+	// Terminals report ctrl+p as a control key with the ctrl modifier
+	ev := tcell.NewEventKey(tcell.KeyCtrlP, 'p', tcell.ModCtrl)
+	if k, _ := ParseKey("ctrl+p"); keyOf(ev) != k {
+		t.Errorf("keyOf(%s) = %+v, want %+v", ev.Name(), keyOf(ev), k)
+	}
+}
+
+func TestKeymapApply(t *testing.T) {
+	km := DefaultKeymap()
+	err := km.Apply(map[string]string{
+		"pause":       "p ctrl+space",
+		"toggle-melt": "F5 q",
+		"reseed":      "none",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for spec, want := range map[string]string{"p": "pause", "ctrl+space": "pause", "f5": "toggle-melt", "q": "toggle-melt", "esc": "quit"} {
+		k, _ := ParseKey(spec)
+		if km[k] != want {
+			t.Errorf("%s is bound to %q, want %q", spec, km[k], want)
+		}
+	}
+	if keys := km.Keys("pause"); len(keys) != 2 {
+		t.Errorf("pause is bound to %v, want only the configured keys", keys)
+	}
+	if keys := km.Keys("reseed"); keys != nil {
+		t.Errorf("reseed is bound to %v, want no keys", keys)
+	}
+
+	for _, bindings := range []map[string]string{
+		{"warp": "w"},
+		{"pause": "ctrl+"},
+		{"pause": "x", "reseed": "x"},
+	} {
+		if err := DefaultKeymap().Apply(bindings); err == nil {
+			t.Errorf("Apply(%v) succeeded, want an error", bindings)
+		}
+	}
+}
+
+func TestDrawStatus(t *testing.T) {
+	r := newTestRunner(t, 40, 3)
+	r.setStatus("saved preset ア-e\u0301!")
+	r.drawStatus()
+	r.screen.Show()
+
+	// Characters are placed by their width on the screen, not their
+	// length in bytes
+	for x, want := range map[int]string{1: "s", 14: "ア", 16: "-", 17: "e\u0301", 18: "!", 19: " "} {
+		if str, _, _ := r.screen.Get(x, 2); str != want {
+			t.Errorf("column %d shows %q, want %q", x, str, want)
+		}
+	}
+}
+
+// gridText returns the runes of the engine's grid.
+func gridText(r *runner) string {
+	g := r.engine.Grid()
+	width, height := g.Size()
+	var b strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			b.WriteRune(g.Get(x, y).Rune)
+		}
+	}
+	return b.String()
+}
+
+// runAction runs the named action on r.
+func runAction(t *testing.T, r *runner, name string) {
+	t.Helper()
+	a, ok := LookupAction(name)
+	if !ok {
+		t.Fatalf("no action %s", name)
+	}
+	a.run(r)
+}
+
+func TestPauseAndReseed(t *testing.T) {
+	r := newTestRunner(t, 20, 5, "-seed", "3")
+	r.tick()
+	frame := gridText(r)
+
+	runAction(t, r, "pause")
+	r.tick()
+	r.tick()
+	if gridText(r) != frame {
+		t.Error("the animation advanced while paused")
+	}
+	runAction(t, r, "pause")
+	r.tick()
+	if gridText(r) == frame {
+		t.Error("the animation did not advance after resuming")
+	}
+
+	runAction(t, r, "reseed")
+	if r.opts.Seed == 3 {
+		t.Error("reseed kept the seed")
+	}
+}
+
+func TestFPSKeys(t *testing.T) {
+	r := newTestRunner(t, 20, 5, "-fps", "20")
+	for _, tc := range []struct {
+		action string
+		want   int
+	}{
+		{"fps-up", 25},
+		{"fps-down", 20},
+	} {
+		runAction(t, r, tc.action)
+		if r.opts.FPS != tc.want {
+			t.Errorf("after %s FPS = %d, want %d", tc.action, r.opts.FPS, tc.want)
+		}
+		// The test runner ticks once an hour until the ticker is reset
+		select {
+		case <-r.ticker.C:
+		case <-time.After(time.Second):
+			t.Fatalf("%s did not reset the ticker", tc.action)
+		}
+		r.ticker.Reset(time.Hour)
+	}
+}
+
+func TestEffectKeys(t *testing.T) {
+	r := newTestRunner(t, 20, 5)
+	km := DefaultKeymap()
+	if km[runeKey('1')] != "toggle-static" || km[runeKey('^')] != "toggle-jitter" {
+		t.Errorf("1 and ^ are bound to %s and %s, want the first and last effect", km[runeKey('1')], km[runeKey('^')])
+	}
+	for _, ch := range effectKeys {
+		a, ok := LookupAction(km[runeKey(ch)])
+		if !ok || a.effect == "" {
+			t.Errorf("%c is bound to %q, want an effect", ch, km[runeKey(ch)])
+			continue
+		}
+		field := options.Lookup(a.effect).Field
+		before := *r.opts
+		a.run(r)
+
+		// Exactly the *Enable field of the effect flips
+		got, old := reflect.ValueOf(*r.opts), reflect.ValueOf(before)
+		for i := 0; i < got.NumField(); i++ {
+			name := got.Type().Field(i).Name
+			changed := !reflect.DeepEqual(got.Field(i).Interface(), old.Field(i).Interface())
+			if changed != (name == field) {
+				t.Errorf("%c changed %s, want only %s", ch, name, field)
+			}
+		}
+	}
+}
+
+func TestSavePresetKey(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	r := newTestRunner(t, 20, 5, "-seed", "9", "-melt")
+	runAction(t, r, "save-preset")
+
+	files, err := filepath.Glob(filepath.Join(dir, "glitch-saver", "presets", "live-*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("saved presets %v, %v, want one live-* preset", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"seed": 9`, `"melt": true`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("preset does not contain %s:\n%s", want, data)
+		}
+	}
+	name := strings.TrimSuffix(filepath.Base(files[0]), ".json")
+	if !strings.Contains(r.status, name) {
+		t.Errorf("status = %q, want the preset name %s", r.status, name)
+	}
+}
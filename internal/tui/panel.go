@@ -0,0 +1,219 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"glitch-saver/internal/options"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+const (
+	// panelWidth is the widest the settings panel gets, in columns.
+	panelWidth = 50
+	// sliderWidth is the number of steps shown by a slider.
+	sliderWidth = 10
+)
+
+// panel is the modal settings panel. It lists the options of Schema by group
+// and applies changes to the running animation as they are made.
+type panel struct {
+	open bool
+	// opts are the options listed, in Schema order.
+	opts []*options.Option
+	// cursor is the index of the selected option in opts.
+	cursor int
+	// top is the first row shown when the list is taller than the screen.
+	top int
+}
+
+// newPanel returns a closed panel listing every option that can be changed
+// while the screensaver runs.
+func newPanel() panel {
+	var p panel
+	for _, opt := range options.Schema {
+		// Presets are loaded on startup, and -all-effects has already
+		// been applied
+		if opt.NoPreset || opt.Name == "all-effects" {
+			continue
+		}
+		p.opts = append(p.opts, opt)
+	}
+	return p
+}
+
+// panelRow is a line of the panel: a group heading or an option.
+type panelRow struct {
+	heading string
+	opt     *options.Option
+}
+
+// rows returns the lines of the panel and the line of the selected option.
+func (p *panel) rows() (rows []panelRow, selected int) {
+	group := ""
+	for i, opt := range p.opts {
+		if opt.Group != group {
+			group = opt.Group
+			rows = append(rows, panelRow{heading: group})
+		}
+		if i == p.cursor {
+			selected = len(rows)
+		}
+		rows = append(rows, panelRow{opt: opt})
+	}
+	return rows, selected
+}
+
+// handleKey moves the selection or changes the selected option. Every key
+// goes to the panel while it is open; Tab and Esc close it.
+func (p *panel) handleKey(r *runner, ev *tcell.EventKey) {
+	steps := 1
+	if ev.Modifiers()&tcell.ModShift != 0 {
+		steps = 5
+	}
+	opt := p.opts[p.cursor]
+	switch ev.Key() {
+	case tcell.KeyTab, tcell.KeyEscape:
+		p.open = false
+	case tcell.KeyUp:
+		p.move(-1)
+	case tcell.KeyDown:
+		p.move(1)
+	case tcell.KeyPgUp:
+		p.move(-10)
+	case tcell.KeyPgDn:
+		p.move(10)
+	case tcell.KeyHome:
+		p.move(-len(p.opts))
+	case tcell.KeyEnd:
+		p.move(len(p.opts))
+	case tcell.KeyLeft:
+		r.apply(opt, -steps)
+	case tcell.KeyRight, tcell.KeyEnter:
+		r.apply(opt, steps)
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'k':
+			p.move(-1)
+		case 'j':
+			p.move(1)
+		case '-', 'h':
+			r.apply(opt, -1)
+		case '+', '=', 'l', ' ':
+			r.apply(opt, 1)
+		}
+	}
+}
+
+// move moves the selection by n options, stopping at either end.
+func (p *panel) move(n int) {
+	p.cursor = max(0, min(len(p.opts)-1, p.cursor+n))
+}
+
+// draw draws the panel over the right side of the screen, scrolled so that
+// the selected option is visible.
+func (p *panel) draw(r *runner) {
+	if !p.open {
+		return
+	}
+	width, height := r.screen.Size()
+	w := min(panelWidth, width)
+	x0 := width - w
+	rows, selected := p.rows()
+
+	// One line each for the title and the key help
+	visible := max(1, height-2)
+	if selected-1 < p.top {
+		// Show the heading along with the first option of a group
+		p.top = max(0, selected-1)
+	}
+	if selected >= p.top+visible {
+		p.top = selected - visible + 1
+	}
+
+	style := tcell.StyleDefault
+	line := func(y int, text string, style tcell.Style) {
+		text = clipText(" "+text, w)
+		drawText(r.screen, x0, y, text+strings.Repeat(" ", w-uniseg.StringWidth(text)), style)
+	}
+
+	line(0, "Settings", style.Bold(true).Reverse(true))
+	for i := 0; i < visible && i < height-1; i++ {
+		y := i + 1
+		if p.top+i >= len(rows) {
+			line(y, "", style)
+			continue
+		}
+		row := rows[p.top+i]
+		switch {
+		case row.opt == nil:
+			line(y, row.heading, style.Bold(true).Underline(true))
+		case p.top+i == selected:
+			line(y, p.format(r, row.opt, w), style.Reverse(true))
+		case !canStep(row.opt):
+			line(y, p.format(r, row.opt, w), style.Dim(true))
+		default:
+			line(y, p.format(r, row.opt, w), style)
+		}
+	}
+	if height > 1 {
+		line(height-1, "up/down select  left/right change  tab close", style.Reverse(true))
+	}
+}
+
+// clipText returns the longest prefix of text that fits in width columns,
+// leaving out a wide character that would only fit in part.
+func clipText(text string, width int) string {
+	state, n, used := -1, 0, 0
+	rest := text
+	for rest != "" {
+		var cluster string
+		var w int
+		cluster, rest, w, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		if used+w > width {
+			break
+		}
+		used += w
+		n += len(cluster)
+	}
+	return text[:n]
+}
+
+// canStep reports whether the panel can change opt. Free-form strings such
+// as -charset are shown but cannot be edited.
+func canStep(opt *options.Option) bool {
+	return opt.Kind != options.String || opt.Choices != nil
+}
+
+// format returns the line showing opt: its name followed by a toggle, a
+// slider or its value.
+func (p *panel) format(r *runner, opt *options.Option, w int) string {
+	nameWidth := 0
+	for _, o := range p.opts {
+		nameWidth = max(nameWidth, len(o.Name))
+	}
+	nameWidth = min(nameWidth, w/2)
+
+	value := opt.Format(r.opts)
+	switch {
+	case opt.Kind == options.Bool && value == "true":
+		value = "[x]"
+	case opt.Kind == options.Bool:
+		value = "[ ]"
+	case opt.Choices != nil:
+		value = "< " + value + " >"
+	case opt.Range != nil && !math.IsInf(opt.Range.Max, 1):
+		v, _ := opt.Value(r.opts).(float64)
+		if n, ok := opt.Value(r.opts).(int); ok {
+			v = float64(n)
+		}
+		filled := int(math.Round((v - opt.Range.Min) / (opt.Range.Max - opt.Range.Min) * sliderWidth))
+		value = "[" + strings.Repeat("#", filled) + strings.Repeat("-", sliderWidth-filled) + "] " + value
+	case value == "":
+		value = "(none)"
+	}
+	return fmt.Sprintf(" %-*s %s", nameWidth, opt.Name, value)
+}
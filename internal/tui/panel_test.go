@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"glitch-saver/internal/options"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestPanel(t *testing.T) {
+	r := newTestRunner(t, 80, 12)
+	p := &r.panel
+	key := func(k tcell.Key, ch rune) {
+		p.handleKey(r, tcell.NewEventKey(k, ch, tcell.ModNone))
+	}
+
+	for _, opt := range p.opts {
+		if opt.NoPreset {
+			t.Errorf("panel lists %s, which cannot be changed while running", opt.Name)
+		}
+	}
+
+	// Select -melt and turn it on
+	for p.opts[p.cursor].Name != "melt" {
+		key(tcell.KeyDown, 0)
+	}
+	key(tcell.KeyRune, ' ')
+	if !r.opts.MeltEnable {
+		t.Error("space did not turn on -melt")
+	}
+
+	// Raise -melt-prob by two steps, then lower it by one
+	key(tcell.KeyDown, 0)
+	key(tcell.KeyRight, 0)
+	key(tcell.KeyRight, 0)
+	key(tcell.KeyLeft, 0)
+	if got := r.opts.MeltProbability; got != 0.15 {
+		t.Errorf("MeltProbability = %v, want 0.15", got)
+	}
+
+	p.open = true
+	r.engine.DrawGlitch()
+	r.engine.Grid().Flush(r.screen)
+	p.draw(r)
+	r.screen.Show()
+	if got := screenText(r.screen, 0); !strings.Contains(got, "Settings") {
+		t.Errorf("first row = %q, want the panel title", got)
+	}
+	found := false
+	for y := 1; y < 11; y++ {
+		found = found || strings.Contains(screenText(r.screen, y), "melt-prob          [##--------] 0.15")
+	}
+	if !found {
+		t.Error("the selected option is not shown as a slider")
+	}
+
+	key(tcell.KeyTab, 0)
+	if p.open {
+		t.Error("Tab did not close the panel")
+	}
+}
+
+func TestPanelFPS(t *testing.T) {
+	r := newTestRunner(t, 80, 24)
+	r.apply(options.Lookup("fps"), 5)
+	if r.opts.FPS != 35 {
+		t.Errorf("FPS = %d, want 35", r.opts.FPS)
+	}
+	r.apply(options.Lookup("color-mode"), 1)
+	if r.opts.ColorMode != "truecolor" {
+		t.Errorf("ColorMode = %s, want truecolor", r.opts.ColorMode)
+	}
+}
+
+func TestPanelWideValues(t *testing.T) {
+	r := newTestRunner(t, 60, 40, "-charset-custom", "アイ", "-scanline-char", strings.Repeat("ウ", 30))
+	r.screen.Fill('.', tcell.StyleDefault)
+	r.panel.open = true
+	r.panel.draw(r)
+	r.screen.Show()
+
+	// find returns the row and column where text starts on the screen.
+	find := func(text string) (int, int) {
+		for y := 0; y < 40; y++ {
+			for x := 0; x < 60; x++ {
+				if str, _, _ := r.screen.Get(x, y); str == text {
+					return x, y
+				}
+			}
+		}
+		t.Fatalf("%q is not on the screen", text)
+		return 0, 0
+	}
+
+	// Characters are placed by their width, and the panel stays within
+	// its columns
+	x, y := find("ア")
+	if str, _, _ := r.screen.Get(x+2, y); str != "イ" {
+		t.Errorf("column after ア shows %q, want イ", str)
+	}
+	if str, _, _ := r.screen.Get(x+4, y); str != " " {
+		t.Errorf("panel is not padded after the value: %q", str)
+	}
+	_, y = find("ウ")
+	if str, _, _ := r.screen.Get(59, y); str != " " {
+		t.Errorf("last column shows %q, want the padding of a clipped wide character", str)
+	}
+	if str, _, _ := r.screen.Get(9, y); str != "." {
+		t.Errorf("the panel spills left of its columns: %q", str)
+	}
+}
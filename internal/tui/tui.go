@@ -1,8 +1,6 @@
 package tui
 
 import (
-	"math/rand"
-	"sync"
 	"time"
 
 	"glitch-saver/internal/effects"
@@ -11,10 +9,9 @@ import (
 	"github.com/gdamore/tcell/v2"
 )
 
-func RunTUI(opts *options.GlitchOptions) (tcell.Screen, error) {
-	// Create a local random number generator
-	rGen := rand.New(rand.NewSource(time.Now().UnixNano()))
-
+// RunTUI runs the screensaver in the terminal until a key bound to the quit
+// action is pressed, handling the other keys of keymap in between frames.
+func RunTUI(opts *options.GlitchOptions, keymap Keymap) (tcell.Screen, error) {
 	// Initialize tcell screen
 	s, err := tcell.NewScreen()
 	if err != nil {
@@ -36,10 +33,10 @@ func RunTUI(opts *options.GlitchOptions) (tcell.Screen, error) {
 	// Get initial screen dimensions
 	width, height := s.Size()
 
-	effects.InitializeEffects(width, height)
-
-	// Use mutex to protect width and height variables that are accessed by both the main loop and event handler
-	var mu sync.Mutex
+	engine := effects.NewEngine(opts)
+	r := &runner{screen: s, engine: engine, opts: opts, panel: newPanel()}
+	r.updateColorMode()
+	engine.Resize(width, height)
 
 	// Create a channel for events and a goroutine to listen for them
 	eventChan := make(chan tcell.Event)
@@ -61,30 +58,49 @@ func RunTUI(opts *options.GlitchOptions) (tcell.Screen, error) {
 		done <- true // Signal the goroutine to stop
 	}()
 
+	r.ticker = ticker
+
 	// Main event loop
 	for {
 		select {
 		case ev := <-eventChan: // Listen on our custom event channel
 			switch ev := ev.(type) {
 			case *tcell.EventResize:
-				mu.Lock()
 				width, height = s.Size() // Update dimensions on resize
-				mu.Unlock()
-				effects.InitializeEffects(width, height)
+				engine.Resize(width, height)
 				s.Clear() // Clear screen on resize to avoid artifacts
 				s.Sync()  // Sync screen after resize
 			case *tcell.EventKey:
-				if ev.Key() == tcell.KeyEscape || ev.Rune() == 'q' {
+				if r.panel.open {
+					r.panel.handleKey(r, ev)
+				} else if name, ok := keymap[keyOf(ev)]; ok {
+					action, _ := LookupAction(name)
+					action.run(r)
+				}
+				if r.quit {
 					return s, nil // Exit the application, returning the screen
 				}
 			}
 		case <-ticker.C: // Handle animation tick
-			mu.Lock()
-			currentWidth := width
-			currentHeight := height
-			mu.Unlock()
-			effects.DrawGlitch(s, currentWidth, currentHeight, rGen, opts) // Pass opts struct
-			s.Show()
+			r.tick()
 		}
 	}
 }
+
+// tick draws the next frame, or redraws the current one while paused, and
+// shows it with the overlays on top.
+func (r *runner) tick() {
+	start := time.Now()
+	if !r.paused {
+		r.engine.DrawGlitch()
+	}
+	r.engine.Grid().FlushMode(r.screen, r.mode)
+	if !r.paused {
+		r.hud.frame(start, time.Since(start))
+	}
+	// Overlays go on the screen only, never into the grid
+	r.hud.draw(r)
+	r.panel.draw(r)
+	r.drawStatus()
+	r.screen.Show()
+}